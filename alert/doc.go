@@ -0,0 +1,3 @@
+// Package alert evaluates exchange rate rules against consecutive BNM responses
+// and dispatches the resulting events to pluggable notification sinks.
+package alert
@@ -0,0 +1,82 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+// Monitor keeps a history of consecutive responses, evaluates rules against it
+// and forwards fired events to the configured notifiers.
+// It is safe for concurrent use by multiple goroutines.
+type Monitor struct {
+	rules     []Rule
+	notifiers []Notifier
+	window    int
+	history   []bnm.Response
+	mu        sync.Mutex
+}
+
+// NewMonitor creates a Monitor evaluating the given rules and notifying all notifiers.
+//
+// Example:
+//
+//	m := alert.NewMonitor(
+//	    []alert.Rule{alert.PercentChange("EUR", 0.5), alert.Threshold("EUR", 20)},
+//	    alert.NewStdoutNotifier(),
+//	)
+func NewMonitor(rules []Rule, notifiers ...Notifier) *Monitor {
+	window := 1
+	for _, r := range rules {
+		window = max(window, r.Window())
+	}
+
+	return &Monitor{
+		rules:     rules,
+		notifiers: notifiers,
+		window:    window,
+	}
+}
+
+// Observe appends the response to the history, evaluates every rule and sends
+// the fired events to all notifiers. Responses must be observed in chronological order.
+//
+// A response with the same Date as the previous one, such as the document
+// returned again on weekends, holidays or repeated polls of a day, is ignored,
+// so that the history holds one response per publication day.
+//
+// It returns the fired events. Delivery failures do not stop other notifiers;
+// they are joined into the returned error.
+func (m *Monitor) Observe(ctx context.Context, res bnm.Response) ([]Event, error) {
+	m.mu.Lock()
+	if n := len(m.history); n > 0 && m.history[n-1].Date == res.Date {
+		m.mu.Unlock()
+		return nil, nil
+	}
+	m.history = append(m.history, res)
+	if len(m.history) > m.window {
+		m.history = append(m.history[:0], m.history[len(m.history)-m.window:]...)
+	}
+
+	var events []Event
+	for _, r := range m.rules {
+		if ev, ok := r.Evaluate(m.history); ok {
+			events = append(events, ev)
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, ev := range events {
+		for _, n := range m.notifiers {
+			if err := n.Notify(ctx, ev); err != nil {
+				errs = append(errs, fmt.Errorf("notify %s: %w", ev.Rule, err))
+			}
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
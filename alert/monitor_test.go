@@ -0,0 +1,87 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/alert"
+)
+
+func TestMonitor_Observe(t *testing.T) {
+	var got []alert.Event
+	m := alert.NewMonitor(
+		[]alert.Rule{alert.PercentChange("EUR", 0.5), alert.NewHigh("EUR", 2)},
+		alert.NotifierFunc(func(_ context.Context, ev alert.Event) error {
+			got = append(got, ev)
+			return nil
+		}),
+	)
+
+	for _, res := range eurHistory(20, 20.01, 20.02, 20.5) {
+		if _, err := m.Observe(t.Context(), res); err != nil {
+			t.Fatalf("Observe() error = %v", err)
+		}
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(got), got)
+	}
+	if got[0].Rule != "new_high" || got[1].Rule != "percent_change" || got[2].Rule != "new_high" {
+		t.Errorf("unexpected events: %+v", got)
+	}
+}
+
+func TestMonitor_ObserveSameDate(t *testing.T) {
+	m := alert.NewMonitor([]alert.Rule{alert.NewHigh("EUR", 2)})
+
+	history := eurHistory(20, 20.01, 20.02)
+	for _, res := range history[:2] {
+		if _, err := m.Observe(t.Context(), res); err != nil {
+			t.Fatalf("Observe() error = %v", err)
+		}
+	}
+
+	// The document is returned again, e.g. on a weekend: it is not a new day.
+	again := history[1]
+	again.Currencies = []bnm.Currency{{Code: "EUR", Value: 20.5}}
+	if events, err := m.Observe(t.Context(), again); err != nil || len(events) != 0 {
+		t.Fatalf("Observe() of the same date = %+v, %v, want no events", events, err)
+	}
+
+	events, err := m.Observe(t.Context(), history[2])
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Rule != "new_high" {
+		t.Errorf("expected a new 2-day high, got %+v", events)
+	}
+}
+
+func TestMonitor_ObserveNotifierError(t *testing.T) {
+	calls := 0
+	m := alert.NewMonitor(
+		[]alert.Rule{alert.Threshold("EUR", 20)},
+		alert.NotifierFunc(func(_ context.Context, _ alert.Event) error {
+			return errors.New("sink down")
+		}),
+		alert.NotifierFunc(func(_ context.Context, _ alert.Event) error {
+			calls++
+			return nil
+		}),
+	)
+
+	history := eurHistory(19, 21)
+	m.Observe(t.Context(), history[0])
+	events, err := m.Observe(t.Context(), history[1])
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(events) != 1 {
+		t.Errorf("expected 1 event, got %d", len(events))
+	}
+	if calls != 1 {
+		t.Errorf("expected second notifier to be called once, got %d", calls)
+	}
+}
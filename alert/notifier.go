@@ -0,0 +1,142 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Notifier delivers alert events to a destination.
+type Notifier interface {
+	// Notify sends the event. It returns an error if delivery fails.
+	Notify(ctx context.Context, ev Event) error
+}
+
+// NotifierFunc adapts an ordinary function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, ev Event) error
+
+// Notify calls f(ctx, ev).
+func (f NotifierFunc) Notify(ctx context.Context, ev Event) error {
+	return f(ctx, ev)
+}
+
+// WebhookNotifier posts events as JSON to an HTTP endpoint.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+var _ Notifier = (*WebhookNotifier)(nil)
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url.
+// If client is nil, http.DefaultClient is used.
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// Notify posts the event as a JSON document.
+// Any non-2xx status code is reported as an error.
+func (n *WebhookNotifier) Notify(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// SMTPNotifier sends events as plain text emails.
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+var _ Notifier = (*SMTPNotifier)(nil)
+
+// NewSMTPNotifier creates an SMTPNotifier that sends mail through the server at
+// addr (host:port) from the given address to all recipients. auth may be nil.
+func NewSMTPNotifier(addr string, auth smtp.Auth, from string, to ...string) *SMTPNotifier {
+	return &SMTPNotifier{addr: addr, auth: auth, from: from, to: to}
+}
+
+// Notify sends the event as an email.
+// The context is only checked before the message is sent.
+func (n *SMTPNotifier) Notify(ctx context.Context, ev Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: [bnm] %s %s\r\n", ev.Code, ev.Rule)
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n", ev.Message)
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, n.to, []byte(msg.String())); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+
+	return nil
+}
+
+// WriterNotifier writes events as text lines to an io.Writer.
+// It is safe for concurrent use by multiple goroutines.
+type WriterNotifier struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+var _ Notifier = (*WriterNotifier)(nil)
+
+// NewWriterNotifier creates a WriterNotifier writing to w.
+func NewWriterNotifier(w io.Writer) *WriterNotifier {
+	return &WriterNotifier{w: w}
+}
+
+// NewStdoutNotifier creates a WriterNotifier writing to the standard output.
+func NewStdoutNotifier() *WriterNotifier {
+	return NewWriterNotifier(os.Stdout)
+}
+
+// Notify writes a single line describing the event.
+func (n *WriterNotifier) Notify(_ context.Context, ev Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, err := fmt.Fprintf(n.w, "%s [%s] %s\n", ev.Date, ev.Rule, ev.Message); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return nil
+}
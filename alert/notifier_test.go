@@ -0,0 +1,139 @@
+package alert_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OsoianMarcel/bnm-go/v2/alert"
+)
+
+var testEvent = alert.Event{
+	Rule:     "threshold",
+	Code:     "EUR",
+	Date:     "05.08.2017",
+	Previous: 19.9,
+	Current:  20.1,
+	Message:  "EUR crossed above 20.0000",
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var got alert.Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	if err := alert.NewWebhookNotifier(ts.URL, nil).Notify(t.Context(), testEvent); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got != testEvent {
+		t.Errorf("expected %+v, got %+v", testEvent, got)
+	}
+}
+
+func TestWebhookNotifier_NotifyStatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	err := alert.NewWebhookNotifier(ts.URL, ts.Client()).Notify(t.Context(), testEvent)
+	if err == nil || !strings.Contains(err.Error(), "status code") {
+		t.Fatalf("expected status code error, got %v", err)
+	}
+}
+
+func TestWriterNotifier_Notify(t *testing.T) {
+	var sb strings.Builder
+	if err := alert.NewWriterNotifier(&sb).Notify(t.Context(), testEvent); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	want := "05.08.2017 [threshold] EUR crossed above 20.0000\n"
+	if sb.String() != want {
+		t.Errorf("expected %q, got %q", want, sb.String())
+	}
+}
+
+// serveFakeSMTP accepts a single SMTP session and sends the received message data to the returned channel.
+func serveFakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 end with .")
+				var msg strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					msg.WriteString(l)
+				}
+				data <- msg.String()
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), data
+}
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	addr, data := serveFakeSMTP(t)
+
+	n := alert.NewSMTPNotifier(addr, nil, "alerts@example.com", "treasury@example.com")
+	if err := n.Notify(t.Context(), testEvent); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	msg := <-data
+	for _, want := range []string{"To: treasury@example.com", "Subject: [bnm] EUR threshold", testEvent.Message} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q does not contain %q", msg, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+// Event describes a rule that fired for a currency.
+type Event struct {
+	Rule     string  `json:"rule"`
+	Code     string  `json:"code"`
+	Date     string  `json:"date"`
+	Previous float32 `json:"previous"`
+	Current  float32 `json:"current"`
+	Message  string  `json:"message"`
+}
+
+// Rule is evaluated against a window of consecutive responses.
+type Rule interface {
+	// Window returns how many responses, including the current one, the rule needs.
+	Window() int
+
+	// Evaluate inspects the history (ordered from oldest to newest, the last
+	// element being the current response) and reports whether the rule fired.
+	Evaluate(history []bnm.Response) (Event, bool)
+}
+
+type thresholdRule struct {
+	code  string
+	level float32
+}
+
+// Threshold returns a rule that fires when the rate of the given currency
+// crosses level in either direction between two consecutive responses.
+func Threshold(code string, level float32) Rule {
+	return thresholdRule{code: code, level: level}
+}
+
+func (r thresholdRule) Window() int { return 2 }
+
+func (r thresholdRule) Evaluate(history []bnm.Response) (Event, bool) {
+	prev, curr, ok := lastTwo(history, r.code)
+	if !ok {
+		return Event{}, false
+	}
+
+	var direction string
+	switch {
+	case prev.Value < r.level && curr.Value >= r.level:
+		direction = "above"
+	case prev.Value > r.level && curr.Value <= r.level:
+		direction = "below"
+	default:
+		return Event{}, false
+	}
+
+	return Event{
+		Rule:     "threshold",
+		Code:     r.code,
+		Date:     history[len(history)-1].Date,
+		Previous: prev.Value,
+		Current:  curr.Value,
+		Message:  fmt.Sprintf("%s crossed %s %.4f (%.4f -> %.4f)", r.code, direction, r.level, prev.Value, curr.Value),
+	}, true
+}
+
+type percentChangeRule struct {
+	code    string
+	percent float64
+}
+
+// PercentChange returns a rule that fires when the rate of the given currency
+// moves by more than percent (e.g. 0.5 for 0.5%) between two consecutive responses.
+func PercentChange(code string, percent float64) Rule {
+	return percentChangeRule{code: code, percent: percent}
+}
+
+func (r percentChangeRule) Window() int { return 2 }
+
+func (r percentChangeRule) Evaluate(history []bnm.Response) (Event, bool) {
+	prev, curr, ok := lastTwo(history, r.code)
+	if !ok || prev.Value == 0 {
+		return Event{}, false
+	}
+
+	change := (float64(curr.Value) - float64(prev.Value)) / float64(prev.Value) * 100
+	if math.Abs(change) <= r.percent {
+		return Event{}, false
+	}
+
+	return Event{
+		Rule:     "percent_change",
+		Code:     r.code,
+		Date:     history[len(history)-1].Date,
+		Previous: prev.Value,
+		Current:  curr.Value,
+		Message:  fmt.Sprintf("%s changed by %+.2f%% (%.4f -> %.4f)", r.code, change, prev.Value, curr.Value),
+	}, true
+}
+
+type extremeRule struct {
+	code string
+	days int
+	high bool
+}
+
+// NewHigh returns a rule that fires when the current rate of the given currency
+// is strictly higher than every rate in the previous days responses.
+func NewHigh(code string, days int) Rule {
+	return extremeRule{code: code, days: days, high: true}
+}
+
+// NewLow returns a rule that fires when the current rate of the given currency
+// is strictly lower than every rate in the previous days responses.
+func NewLow(code string, days int) Rule {
+	return extremeRule{code: code, days: days}
+}
+
+func (r extremeRule) Window() int { return r.days + 1 }
+
+func (r extremeRule) Evaluate(history []bnm.Response) (Event, bool) {
+	if r.days <= 0 || len(history) < r.days+1 {
+		return Event{}, false
+	}
+
+	window := history[len(history)-r.days-1:]
+	curr, ok := window[len(window)-1].FindByCode(r.code)
+	if !ok {
+		return Event{}, false
+	}
+
+	var prev float32
+	for i, res := range window[:len(window)-1] {
+		c, ok := res.FindByCode(r.code)
+		if !ok {
+			return Event{}, false
+		}
+		if i == 0 || (r.high && c.Value > prev) || (!r.high && c.Value < prev) {
+			prev = c.Value
+		}
+		if (r.high && c.Value >= curr.Value) || (!r.high && c.Value <= curr.Value) {
+			return Event{}, false
+		}
+	}
+
+	name, kind := "new_low", "low"
+	if r.high {
+		name, kind = "new_high", "high"
+	}
+
+	return Event{
+		Rule:     name,
+		Code:     r.code,
+		Date:     window[len(window)-1].Date,
+		Previous: prev,
+		Current:  curr.Value,
+		Message:  fmt.Sprintf("%s reached a new %d-day %s of %.4f (previous %s %.4f)", r.code, r.days, kind, curr.Value, kind, prev),
+	}, true
+}
+
+// lastTwo returns the currency with the given code from the last two responses of the history.
+func lastTwo(history []bnm.Response, code string) (bnm.Currency, bnm.Currency, bool) {
+	if len(history) < 2 {
+		return bnm.Currency{}, bnm.Currency{}, false
+	}
+
+	prev, ok := history[len(history)-2].FindByCode(code)
+	if !ok {
+		return bnm.Currency{}, bnm.Currency{}, false
+	}
+
+	curr, ok := history[len(history)-1].FindByCode(code)
+	if !ok {
+		return bnm.Currency{}, bnm.Currency{}, false
+	}
+
+	return prev, curr, true
+}
@@ -0,0 +1,58 @@
+package alert_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/alert"
+)
+
+func eurHistory(values ...float32) []bnm.Response {
+	history := make([]bnm.Response, 0, len(values))
+	day := time.Date(2017, time.August, 5, 0, 0, 0, 0, bnm.Location())
+	for i, v := range values {
+		history = append(history, bnm.Response{
+			Date:       day.AddDate(0, 0, i).Format("02.01.2006"),
+			Currencies: []bnm.Currency{{Code: "EUR", Value: v}},
+		})
+	}
+
+	return history
+}
+
+func TestRules_Evaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    alert.Rule
+		history []bnm.Response
+		want    bool
+	}{
+		{"threshold single response", alert.Threshold("EUR", 20), eurHistory(21), false},
+		{"threshold crossed above", alert.Threshold("EUR", 20), eurHistory(19.9, 20.1), true},
+		{"threshold crossed below", alert.Threshold("EUR", 20), eurHistory(20.1, 19.9), true},
+		{"threshold not crossed", alert.Threshold("EUR", 20), eurHistory(20.1, 20.2), false},
+		{"threshold unknown code", alert.Threshold("USD", 20), eurHistory(19, 21), false},
+		{"percent change above", alert.PercentChange("EUR", 0.5), eurHistory(20, 20.2), true},
+		{"percent change below", alert.PercentChange("EUR", 0.5), eurHistory(20, 19.8), true},
+		{"percent change within", alert.PercentChange("EUR", 0.5), eurHistory(20, 20.05), false},
+		{"percent change zero previous", alert.PercentChange("EUR", 0.5), eurHistory(0, 20), false},
+		{"new high", alert.NewHigh("EUR", 3), eurHistory(20, 20.5, 20.2, 20.6), true},
+		{"no new high", alert.NewHigh("EUR", 3), eurHistory(20, 20.6, 20.2, 20.5), false},
+		{"new high short history", alert.NewHigh("EUR", 3), eurHistory(20, 20.6), false},
+		{"new low", alert.NewLow("EUR", 2), eurHistory(20, 19.9, 19.8), true},
+		{"no new low", alert.NewLow("EUR", 2), eurHistory(20, 19.7, 19.8), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, ok := tt.rule.Evaluate(tt.history)
+			if ok != tt.want {
+				t.Fatalf("Evaluate() fired = %v, want %v (event %+v)", ok, tt.want, ev)
+			}
+			if ok && (ev.Code == "" || ev.Message == "") {
+				t.Errorf("incomplete event: %+v", ev)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package bnm
+
+import "context"
+
+// Archiver retains the original documents fetched from the BNM API.
+type Archiver interface {
+	// Archive stores the raw body fetched for the query together with its metadata.
+	// It must not modify or retain raw after returning.
+	Archive(ctx context.Context, query Query, raw []byte, meta Meta) error
+}
+
+// ArchiverFunc adapts an ordinary function to the Archiver interface.
+type ArchiverFunc func(ctx context.Context, query Query, raw []byte, meta Meta) error
+
+// Archive calls f(ctx, query, raw, meta).
+func (f ArchiverFunc) Archive(ctx context.Context, query Query, raw []byte, meta Meta) error {
+	return f(ctx, query, raw, meta)
+}
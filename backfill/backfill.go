@@ -0,0 +1,287 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/calendar"
+	"github.com/OsoianMarcel/bnm-go/v2/internal/atomicfile"
+)
+
+const dateFormat = "2006-01-02"
+
+// NonPublicationPolicy controls how a backfill handles the dates for which BNM
+// publishes no rates of their own, such as weekends and public holidays.
+type NonPublicationPolicy int
+
+const (
+	// FetchAll fetches every date; dates answered with the rates of another
+	// date are reported as missing.
+	FetchAll NonPublicationPolicy = iota
+
+	// SkipNonPublication neither fetches nor reports non-publication days.
+	SkipNonPublication
+
+	// CarryForward writes the rates of the previous publication day, which
+	// are in force on a non-publication day, under that day.
+	CarryForward
+)
+
+// Config configures a backfill.
+type Config struct {
+	// From and To are the first and last dates to fetch, inclusive.
+	From, To time.Time
+
+	// Langs are the languages to fetch for every date. Defaults to bnm.LANG_EN.
+	Langs []string
+
+	// Checkpoint is the path of the file persisting the progress.
+	// If empty, the progress is not persisted.
+	Checkpoint string
+
+	// RetryFailed retries the days that failed in a previous run of the same
+	// checkpoint before resuming.
+	RetryFailed bool
+
+	// NonPublication controls how non-publication days are handled. Defaults to FetchAll.
+	NonPublication NonPublicationPolicy
+
+	// Calendar tells which dates are publication days. Defaults to calendar.Default.
+	Calendar *calendar.Calendar
+}
+
+// Day identifies a date and language of a backfill.
+type Day struct {
+	Date  string `json:"date"`
+	Lang  string `json:"lang"`
+	Error string `json:"error,omitempty"`
+}
+
+// Summary reports the outcome of a backfill, including previous runs of the same checkpoint.
+type Summary struct {
+	// Written is the number of responses written to the sink.
+	Written int `json:"written"`
+
+	// Skipped is the number of non-publication days skipped with SkipNonPublication.
+	Skipped int `json:"skipped,omitempty"`
+
+	// CarriedForward is the number of non-publication days written with the
+	// rates of the previous publication day with CarryForward.
+	CarriedForward int `json:"carried_forward,omitempty"`
+
+	// Missing lists the days for which BNM published no rates of their own,
+	// e.g. weekends and holidays answered with the rates of a previous date.
+	Missing []Day `json:"missing,omitempty"`
+
+	// Failed lists the days that could not be fetched or written.
+	Failed []Day `json:"failed,omitempty"`
+}
+
+// checkpoint is the JSON document persisting the progress of a backfill.
+type checkpoint struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Langs   []string `json:"langs"`
+	Next    string   `json:"next"`
+	Summary Summary  `json:"summary"`
+}
+
+// Run fetches every date of the range in every language through the client
+// and writes the responses to the sink. Rate limiting, retries and caching are
+// those configured on the client, e.g. with bnm.WithRateLimit.
+//
+// Days that fail are recorded in the summary and do not stop the backfill.
+// The progress is saved in the checkpoint file after every date, so that a run
+// stopped by an error or a canceled context resumes where it stopped. A
+// checkpoint resumes runs with the same From and languages and a To that is
+// the same or later.
+// Run returns the summary together with the context error, if any.
+func Run(ctx context.Context, client *bnm.Client, sink Sink, cfg Config) (Summary, error) {
+	from, to := day(cfg.From), day(cfg.To)
+	if to.Before(from) {
+		return Summary{}, errors.New("backfill: the range ends before it starts")
+	}
+
+	langs := cfg.Langs
+	if len(langs) == 0 {
+		langs = []string{bnm.LANG_EN}
+	}
+
+	r := runner{client: client, sink: sink, policy: cfg.NonPublication, calendar: cfg.Calendar}
+	if r.calendar == nil {
+		r.calendar = calendar.Default
+	}
+
+	cp := checkpoint{
+		From:  from.Format(dateFormat),
+		To:    to.Format(dateFormat),
+		Langs: langs,
+		Next:  from.Format(dateFormat),
+	}
+	if cfg.Checkpoint != "" {
+		saved, err := loadCheckpoint(cfg.Checkpoint)
+		if err != nil {
+			return Summary{}, fmt.Errorf("backfill: %w", err)
+		}
+		if saved != nil {
+			// The range may only be extended, e.g. by running again up to today.
+			if saved.From != cp.From || saved.To > cp.To || !slices.Equal(saved.Langs, cp.Langs) {
+				return Summary{}, fmt.Errorf("backfill: checkpoint %s was created for another range or languages", cfg.Checkpoint)
+			}
+			saved.To = cp.To
+			cp = *saved
+		}
+	}
+
+	save := func() error {
+		if cfg.Checkpoint == "" {
+			return nil
+		}
+		if err := saveCheckpoint(cfg.Checkpoint, cp); err != nil {
+			return fmt.Errorf("backfill: %w", err)
+		}
+		return nil
+	}
+
+	if cfg.RetryFailed {
+		failed := cp.Summary.Failed
+		cp.Summary.Failed = nil
+		for i, d := range failed {
+			date, err := time.ParseInLocation(dateFormat, d.Date, bnm.Location())
+			if err != nil {
+				return cp.Summary, fmt.Errorf("backfill: checkpoint: %w", err)
+			}
+			if err := r.process(ctx, bnm.NewQuery(date, d.Lang), &cp.Summary); err != nil {
+				cp.Summary.Failed = append(cp.Summary.Failed, failed[i:]...)
+				return cp.Summary, errors.Join(err, save())
+			}
+		}
+		if err := save(); err != nil {
+			return cp.Summary, err
+		}
+	}
+
+	next, err := time.ParseInLocation(dateFormat, cp.Next, bnm.Location())
+	if err != nil {
+		return cp.Summary, fmt.Errorf("backfill: checkpoint: %w", err)
+	}
+
+	for date := next; !date.After(to); date = date.AddDate(0, 0, 1) {
+		summary := cp.Summary
+		summary.Missing = slices.Clone(summary.Missing)
+		summary.Failed = slices.Clone(summary.Failed)
+		for _, lang := range langs {
+			if err := r.process(ctx, bnm.NewQuery(date, lang), &summary); err != nil {
+				// The date is processed again on resume, so drop its partial results.
+				return cp.Summary, err
+			}
+		}
+
+		cp.Summary = summary
+		cp.Next = date.AddDate(0, 0, 1).Format(dateFormat)
+		if err := save(); err != nil {
+			return cp.Summary, err
+		}
+	}
+
+	return cp.Summary, nil
+}
+
+// runner processes the days of a backfill.
+type runner struct {
+	client   *bnm.Client
+	sink     Sink
+	policy   NonPublicationPolicy
+	calendar *calendar.Calendar
+}
+
+// process fetches a single day and records its outcome in the summary.
+// It only returns an error when the context is done.
+func (r runner) process(ctx context.Context, query bnm.Query, summary *Summary) error {
+	d := Day{Date: query.Day().Format(dateFormat), Lang: query.Lang}
+
+	fetch, carried := query, false
+	if r.policy != FetchAll && !r.calendar.IsPublicationDay(query.Day()) {
+		if r.policy == SkipNonPublication {
+			summary.Skipped++
+			return nil
+		}
+		fetch, carried = bnm.NewQuery(r.calendar.PreviousPublicationDay(query.Day()), query.Lang), true
+	}
+
+	res, meta, err := r.client.FetchWithMeta(ctx, fetch)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	switch {
+	case errors.Is(err, bnm.ErrNoRatesPublished), errors.Is(err, bnm.ErrDateMismatch):
+		summary.Missing = append(summary.Missing, d)
+		return nil
+	case err != nil:
+		d.Error = err.Error()
+		summary.Failed = append(summary.Failed, d)
+		return nil
+	case meta.DateMismatch():
+		summary.Missing = append(summary.Missing, d)
+		return nil
+	}
+
+	if err := r.sink.Write(ctx, query, res, meta); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		d.Error = fmt.Sprintf("write: %v", err)
+		summary.Failed = append(summary.Failed, d)
+		return nil
+	}
+
+	summary.Written++
+	if carried {
+		summary.CarriedForward++
+	}
+	return nil
+}
+
+// day returns midnight in Europe/Chisinau of the day a query for t requests.
+func day(t time.Time) time.Time {
+	return bnm.NewQuery(t, "").Day()
+}
+
+// loadCheckpoint reads the checkpoint file, returning nil if it does not exist.
+func loadCheckpoint(name string) (*checkpoint, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("unmarshal checkpoint: %w", err)
+	}
+
+	return &cp, nil
+}
+
+func saveCheckpoint(name string, cp checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	if err := atomicfile.Write(name, data); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package backfill_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/backfill"
+)
+
+// fakeUpstream answers like BNM: weekends get the rates of the previous Friday.
+type fakeUpstream struct {
+	mu    sync.Mutex
+	fail  map[string]bool
+	calls int
+}
+
+func (f *fakeUpstream) get(_ context.Context, rawURL string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	date := u.Query().Get("date")
+	if f.fail[date] {
+		return nil, errors.New("connection reset")
+	}
+
+	d, err := time.Parse("02.01.2006", date)
+	if err != nil {
+		return nil, err
+	}
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		d = d.AddDate(0, 0, -1)
+	}
+
+	return []byte(`<ValCurs Date="` + d.Format("02.01.2006") + `"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`), nil
+}
+
+type memorySink struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (s *memorySink) Write(_ context.Context, query bnm.Query, _ bnm.Response, _ bnm.Meta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, query.ID())
+	return nil
+}
+
+func date(day int) time.Time {
+	// January 2025: the 4th and 5th are a weekend.
+	return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRun(t *testing.T) {
+	upstream := &fakeUpstream{fail: map[string]bool{"07.01.2025": true}}
+	client := bnm.NewClient(bnm.WithGetRequest(upstream.get))
+	sink := &memorySink{}
+
+	summary, err := backfill.Run(t.Context(), client, sink, backfill.Config{
+		From:  date(2),
+		To:    date(8),
+		Langs: []string{bnm.LANG_EN, bnm.LANG_RO},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if summary.Written != 8 {
+		t.Errorf("Written = %d, want 8", summary.Written)
+	}
+	if len(summary.Missing) != 4 || summary.Missing[0].Date != "2025-01-04" {
+		t.Errorf("unexpected missing days %+v", summary.Missing)
+	}
+	if len(summary.Failed) != 2 || summary.Failed[0].Date != "2025-01-07" || summary.Failed[0].Error == "" {
+		t.Errorf("unexpected failed days %+v", summary.Failed)
+	}
+}
+
+func TestRun_NonMidnightRange(t *testing.T) {
+	upstream := &fakeUpstream{}
+	client := bnm.NewClient(bnm.WithGetRequest(upstream.get))
+	sink := &memorySink{}
+
+	// 22:30 UTC on the 9th is already the 10th in Chisinau.
+	instant := time.Date(2025, 1, 9, 22, 30, 0, 0, time.UTC)
+	summary, err := backfill.Run(t.Context(), client, sink, backfill.Config{From: instant, To: instant})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := bnm.NewQuery(instant, bnm.LANG_EN).ID()
+	if summary.Written != 1 || len(sink.keys) != 1 || sink.keys[0] != want {
+		t.Errorf("expected %s to be written, got %v (%+v)", want, sink.keys, summary)
+	}
+}
+
+func TestRun_ResumeFromCheckpoint(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+	upstream := &fakeUpstream{fail: map[string]bool{"03.01.2025": true}}
+	sink := &memorySink{}
+	cfg := backfill.Config{From: date(2), To: date(8), Checkpoint: checkpoint}
+
+	// Cancel the run while it fetches the 6th.
+	ctx, cancel := context.WithCancel(t.Context())
+	client := bnm.NewClient(bnm.WithGetRequest(func(ctx context.Context, rawURL string) ([]byte, error) {
+		if u, _ := url.Parse(rawURL); u.Query().Get("date") == "06.01.2025" {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return upstream.get(ctx, rawURL)
+	}))
+
+	summary, err := backfill.Run(ctx, client, sink, cfg)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if summary.Written != 1 || len(summary.Failed) != 1 || len(summary.Missing) != 2 {
+		t.Fatalf("unexpected summary after interruption %+v", summary)
+	}
+
+	// Resume, retrying the failed day now that the upstream recovered.
+	upstream.fail = nil
+	upstream.calls = 0
+	cfg.RetryFailed = true
+	summary, err = backfill.Run(t.Context(), bnm.NewClient(bnm.WithGetRequest(upstream.get)), sink, cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if upstream.calls != 4 {
+		t.Errorf("expected the resumed run to fetch the 3rd and 6th to 8th only, got %d calls", upstream.calls)
+	}
+	if summary.Written != 5 || len(summary.Failed) != 0 || len(summary.Missing) != 2 {
+		t.Errorf("unexpected summary after resume %+v", summary)
+	}
+
+	// Extending the range, e.g. on a later day, only fetches the new dates.
+	upstream.calls = 0
+	cfg.To = date(9)
+	summary, err = backfill.Run(t.Context(), bnm.NewClient(bnm.WithGetRequest(upstream.get)), sink, cfg)
+	if err != nil {
+		t.Fatalf("Run() with an extended range error = %v", err)
+	}
+	if upstream.calls != 1 || summary.Written != 6 {
+		t.Errorf("expected the extended run to fetch the 9th only, got %d calls and %+v", upstream.calls, summary)
+	}
+
+	// A checkpoint cannot be reused for another range.
+	for _, other := range []backfill.Config{
+		{From: date(3), To: date(9), Checkpoint: checkpoint},
+		{From: date(2), To: date(8), Checkpoint: checkpoint},
+	} {
+		if _, err := backfill.Run(t.Context(), client, sink, other); err == nil {
+			t.Errorf("expected error for mismatched checkpoint %v to %v, got nil", other.From, other.To)
+		}
+	}
+}
+
+func TestRun_InvalidRange(t *testing.T) {
+	_, err := backfill.Run(t.Context(), bnm.NewClient(), &memorySink{}, backfill.Config{From: date(8), To: date(2)})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestRun_NonPublicationPolicy(t *testing.T) {
+	// 2 to 8 January 2025 has three publication days: the 4th and 5th are a
+	// weekend and the 7th and 8th are Orthodox Christmas.
+	tests := []struct {
+		name                      string
+		policy                    backfill.NonPublicationPolicy
+		written, skipped, carried int
+	}{
+		{"fetch", backfill.FetchAll, 5, 0, 0}, // weekends answer with Friday's rates
+		{"skip", backfill.SkipNonPublication, 3, 4, 0},
+		{"carry", backfill.CarryForward, 7, 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &fakeUpstream{}
+			client := bnm.NewClient(bnm.WithGetRequest(upstream.get))
+			sink := &memorySink{}
+
+			summary, err := backfill.Run(t.Context(), client, sink, backfill.Config{
+				From:           date(2),
+				To:             date(8),
+				Langs:          []string{bnm.LANG_EN},
+				NonPublication: tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if summary.Written != tt.written || summary.Skipped != tt.skipped || summary.CarriedForward != tt.carried {
+				t.Errorf("summary = %+v, want written %d, skipped %d, carried %d", summary, tt.written, tt.skipped, tt.carried)
+			}
+			if len(sink.keys) != tt.written {
+				t.Errorf("sink received %d writes, want %d", len(sink.keys), tt.written)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// Package backfill fetches exchange rates for a range of dates through a
+// bnm.Client and writes them to a Sink, persisting its progress in a
+// checkpoint file so that an interrupted run can be resumed.
+package backfill
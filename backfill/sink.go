@@ -0,0 +1,118 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/internal/atomicfile"
+)
+
+// Sink receives the responses fetched by a backfill.
+type Sink interface {
+	// Write stores the response fetched for the query.
+	Write(ctx context.Context, query bnm.Query, res bnm.Response, meta bnm.Meta) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface.
+type SinkFunc func(ctx context.Context, query bnm.Query, res bnm.Response, meta bnm.Meta) error
+
+// Write calls f(ctx, query, res, meta).
+func (f SinkFunc) Write(ctx context.Context, query bnm.Query, res bnm.Response, meta bnm.Meta) error {
+	return f(ctx, query, res, meta)
+}
+
+// CacheSink returns a Sink storing responses in a bnm.Cache under the query ID,
+// together with their metadata if the cache implements bnm.MetaCache.
+func CacheSink(cache bnm.Cache) Sink {
+	return SinkFunc(func(ctx context.Context, query bnm.Query, res bnm.Response, meta bnm.Meta) error {
+		if mc, ok := cache.(bnm.MetaCache); ok {
+			return mc.SetWithMeta(ctx, query.ID(), res, meta)
+		}
+		return cache.Set(ctx, query.ID(), res)
+	})
+}
+
+// DirSink stores responses as JSON documents named <dir>/<lang>/<YYYY-MM-DD>.json.
+type DirSink struct {
+	dir string
+}
+
+var _ Sink = (*DirSink)(nil)
+
+// dirDocument is the JSON document written by DirSink.
+type dirDocument struct {
+	Response bnm.Response `json:"response"`
+	Meta     bnm.Meta     `json:"meta"`
+}
+
+// NewDirSink creates a DirSink writing to dir, creating it if needed.
+func NewDirSink(dir string) (*DirSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create sink directory: %w", err)
+	}
+
+	return &DirSink{dir: dir}, nil
+}
+
+// Write stores the response and its metadata, overwriting any previous document.
+func (s *DirSink) Write(_ context.Context, query bnm.Query, res bnm.Response, meta bnm.Meta) error {
+	data, err := json.MarshalIndent(dirDocument{Response: res, Meta: meta}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal document: %w", err)
+	}
+
+	dir := filepath.Join(s.dir, query.Lang)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	name := filepath.Join(dir, query.Day().Format(dateFormat)+".json")
+	if err := atomicfile.Write(name, data); err != nil {
+		return fmt.Errorf("write document: %w", err)
+	}
+
+	return nil
+}
+
+// Execer executes SQL statements, as implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// SQLSink stores one row per currency with a caller-supplied statement,
+// so that it works with any SQL dialect and schema.
+type SQLSink struct {
+	db   Execer
+	stmt string
+}
+
+var _ Sink = (*SQLSink)(nil)
+
+// NewSQLSink creates a SQLSink executing stmt for every currency of a response
+// with the arguments: date (YYYY-MM-DD of the query), language, currency code,
+// numeric code, nominal, name and value.
+//
+// Example:
+//
+//	sink := backfill.NewSQLSink(db, `INSERT INTO rates (date, lang, code, num_code, nominal, name, value)
+//	    VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`)
+func NewSQLSink(db Execer, stmt string) *SQLSink {
+	return &SQLSink{db: db, stmt: stmt}
+}
+
+// Write executes the statement for every currency of the response.
+func (s *SQLSink) Write(ctx context.Context, query bnm.Query, res bnm.Response, _ bnm.Meta) error {
+	date := query.Day().Format(dateFormat)
+	for _, c := range res.Currencies {
+		if _, err := s.db.ExecContext(ctx, s.stmt, date, query.Lang, c.Code, c.NumCode, c.Nominal, c.Name, c.Value); err != nil {
+			return fmt.Errorf("insert %s: %w", c.Code, err)
+		}
+	}
+
+	return nil
+}
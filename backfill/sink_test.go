@@ -0,0 +1,126 @@
+package backfill_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/backfill"
+)
+
+var sinkResponse = bnm.Response{
+	Date: "03.01.2025",
+	Currencies: []bnm.Currency{
+		{Code: "EUR", NumCode: 978, Nominal: 1, Name: "Euro", Value: 19.5},
+		{Code: "USD", NumCode: 840, Nominal: 1, Name: "US Dollar", Value: 18.2},
+	},
+}
+
+func TestDirSink(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := backfill.NewDirSink(dir)
+	if err != nil {
+		t.Fatalf("NewDirSink() error = %v", err)
+	}
+
+	meta := bnm.Meta{SHA256: "abc"}
+	if err := sink.Write(t.Context(), bnm.NewQuery(date(3), bnm.LANG_RO), sinkResponse, meta); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "ro", "2025-01-03.json"))
+	if err != nil {
+		t.Fatalf("read document: %v", err)
+	}
+
+	var doc struct {
+		Response bnm.Response `json:"response"`
+		Meta     bnm.Meta     `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal document: %v", err)
+	}
+	if doc.Response.Date != sinkResponse.Date || len(doc.Response.Currencies) != 2 || doc.Meta.SHA256 != "abc" {
+		t.Errorf("unexpected document %s", data)
+	}
+}
+
+func TestDirSink_NonMidnightQuery(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := backfill.NewDirSink(dir)
+	if err != nil {
+		t.Fatalf("NewDirSink() error = %v", err)
+	}
+
+	// 22:30 UTC on the 9th is already the 10th in Chisinau.
+	query := bnm.NewQuery(time.Date(2025, 1, 9, 22, 30, 0, 0, time.UTC), bnm.LANG_EN)
+	if err := sink.Write(t.Context(), query, sinkResponse, bnm.Meta{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "en", "2025-01-10.json")); err != nil {
+		t.Errorf("expected the document of the Chisinau day: %v", err)
+	}
+}
+
+type fakeExecer struct {
+	args [][]any
+}
+
+func (f *fakeExecer) ExecContext(_ context.Context, _ string, args ...any) (sql.Result, error) {
+	f.args = append(f.args, args)
+	return nil, nil
+}
+
+func TestSQLSink(t *testing.T) {
+	db := &fakeExecer{}
+	sink := backfill.NewSQLSink(db, "INSERT INTO rates VALUES (?, ?, ?, ?, ?, ?, ?)")
+
+	if err := sink.Write(t.Context(), bnm.NewQuery(date(3), bnm.LANG_EN), sinkResponse, bnm.Meta{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(db.args) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(db.args))
+	}
+	want := []any{"2025-01-03", "en", "USD", 840, 1, "US Dollar", float32(18.2)}
+	for i := range want {
+		if db.args[1][i] != want[i] {
+			t.Errorf("row arguments = %v, want %v", db.args[1], want)
+			break
+		}
+	}
+}
+
+func TestSQLSink_NonMidnightQuery(t *testing.T) {
+	db := &fakeExecer{}
+	sink := backfill.NewSQLSink(db, "INSERT INTO rates VALUES (?, ?, ?, ?, ?, ?, ?)")
+
+	query := bnm.NewQuery(time.Date(2025, 1, 9, 22, 30, 0, 0, time.UTC), bnm.LANG_EN)
+	if err := sink.Write(t.Context(), query, sinkResponse, bnm.Meta{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(db.args) == 0 || db.args[0][0] != "2025-01-10" {
+		t.Errorf("expected rows dated 2025-01-10, got %v", db.args)
+	}
+}
+
+func TestCacheSink(t *testing.T) {
+	cache, _ := bnm.NewMemoryCache(10)
+	query := bnm.NewQuery(date(3), bnm.LANG_EN)
+
+	if err := backfill.CacheSink(cache).Write(t.Context(), query, sinkResponse, bnm.Meta{SHA256: "abc"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	_, meta, err := cache.GetWithMeta(t.Context(), query.ID())
+	if err != nil || meta.SHA256 != "abc" {
+		t.Errorf("GetWithMeta() = %+v, %v", meta, err)
+	}
+}
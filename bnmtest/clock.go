@@ -0,0 +1,138 @@
+package bnmtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+// FakeClock is a bnm.Clock that only moves when Advance or Set is called.
+// Timers fire synchronously during Advance, in deadline order.
+// It is safe for concurrent use by multiple goroutines.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+var _ bnm.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the fake time once it has advanced by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a Timer firing once the fake time has advanced by d.
+// A non-positive d fires immediately.
+func (c *FakeClock) NewTimer(d time.Duration) bnm.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance moves the fake time forward by d and fires the timers that are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set moves the fake time to t and fires the timers that are due.
+// Moving backwards fires nothing.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(t)
+}
+
+// Waiters returns the number of timers that have not fired nor been stopped.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are pending, e.g. until a goroutine
+// under test has started waiting on the clock and can be advanced past.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *FakeClock) setLocked(t time.Time) {
+	c.now = t
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(t) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- t
+	}
+	clear(c.timers[len(pending):])
+	c.timers = pending
+	c.cond.Broadcast()
+}
+
+// removeLocked removes the timer from the pending ones and reports whether it was pending.
+func (c *FakeClock) removeLocked(t *fakeTimer) bool {
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.removeLocked(t)
+}
@@ -0,0 +1,72 @@
+package bnmtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+func TestFakeClock_Advance(t *testing.T) {
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	clock := bnmtest.NewFakeClock(start)
+
+	early := clock.NewTimer(time.Minute)
+	late := clock.After(time.Hour)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-early.C():
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	if got := <-early.C(); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("timer fired at %v", got)
+	}
+	if clock.Waiters() != 1 {
+		t.Errorf("expected 1 pending timer, got %d", clock.Waiters())
+	}
+
+	clock.Set(start.Add(2 * time.Hour))
+	if got := <-late; !got.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("After fired at %v", got)
+	}
+	if !clock.Now().Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("unexpected time %v", clock.Now())
+	}
+}
+
+func TestFakeClock_Stop(t *testing.T) {
+	clock := bnmtest.NewFakeClock(time.Now())
+
+	timer := clock.NewTimer(time.Second)
+	if !timer.Stop() {
+		t.Error("expected Stop to report a pending timer")
+	}
+	if timer.Stop() {
+		t.Error("expected second Stop to report false")
+	}
+
+	clock.Advance(time.Minute)
+	select {
+	case <-timer.C():
+		t.Error("stopped timer fired")
+	default:
+	}
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	clock := bnmtest.NewFakeClock(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		<-clock.After(time.Second)
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-done
+}
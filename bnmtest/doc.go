@@ -0,0 +1,11 @@
+// Package bnmtest provides utilities for testing code built on the bnm package.
+//
+// Server is a fake BNM API serving fixtures and synthetic rates for any date,
+// with injectable faults such as latency, server errors, rate limiting,
+// truncated documents and HTML maintenance pages. NewClient starts one for a
+// test and returns a Client pointed at it.
+//
+// FakeClock is a bnm.Clock whose time only moves when the test advances it,
+// so that revalidation, backoff, hedging delays, rate limits and circuit breaker
+// cool-downs can be tested deterministically and without sleeping.
+package bnmtest
@@ -0,0 +1,79 @@
+package bnmtest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Fault makes the Server misbehave for a request. body is the document the
+// request would be answered with. A Fault returns true if it has answered the
+// request itself, or false to let the document be served, e.g. after a delay.
+type Fault func(w http.ResponseWriter, r *http.Request, body []byte) bool
+
+// Latency delays the answer by d, or until the request is canceled.
+func Latency(d time.Duration) Fault {
+	return func(_ http.ResponseWriter, r *http.Request, _ []byte) bool {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-r.Context().Done():
+		case <-timer.C:
+		}
+		return false
+	}
+}
+
+// ServerError answers with the given status code, e.g. http.StatusBadGateway.
+func ServerError(status int) Fault {
+	return func(w http.ResponseWriter, _ *http.Request, _ []byte) bool {
+		http.Error(w, http.StatusText(status), status)
+		return true
+	}
+}
+
+// TooManyRequests answers 429 Too Many Requests with a Retry-After header
+// of retryAfter, rounded up to whole seconds.
+func TooManyRequests(retryAfter time.Duration) Fault {
+	return func(w http.ResponseWriter, _ *http.Request, _ []byte) bool {
+		seconds := int((retryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return true
+	}
+}
+
+// TruncatedXML answers 200 OK with the first half of the document.
+func TruncatedXML() Fault {
+	return func(w http.ResponseWriter, _ *http.Request, body []byte) bool {
+		w.Header().Set("Content-Type", xmlContentType)
+		w.Write(body[:len(body)/2])
+		return true
+	}
+}
+
+// maintenancePage is an HTML page like the ones served during BNM maintenance.
+const maintenancePage = `<!DOCTYPE html>
+<html>
+<head><title>Banca Națională a Moldovei</title></head>
+<body><h1>Site under maintenance</h1><p>Please try again later.</p></body>
+</html>
+`
+
+// HTMLErrorPage answers 200 OK with an HTML maintenance page instead of the document.
+func HTMLErrorPage() Fault {
+	return func(w http.ResponseWriter, _ *http.Request, _ []byte) bool {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(maintenancePage))
+		return true
+	}
+}
+
+// EmptyPayload answers 200 OK with an empty body.
+func EmptyPayload() Fault {
+	return func(w http.ResponseWriter, _ *http.Request, _ []byte) bool {
+		w.Header().Set("Content-Type", xmlContentType)
+		return true
+	}
+}
@@ -0,0 +1,111 @@
+package bnmtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+// documentNames are the name attributes of the ValCurs element by language.
+var documentNames = map[string]string{
+	bnm.LANG_EN: "Official exchange rate",
+	bnm.LANG_RO: "Cursul oficial de schimb",
+	bnm.LANG_RU: "Официальный курс",
+}
+
+// syntheticCurrency is a currency quoted in synthetic documents.
+type syntheticCurrency struct {
+	id      string
+	numCode int
+	code    string
+	nominal int
+	names   map[string]string
+	base    float64
+}
+
+// syntheticCurrencies are the currencies of synthetic documents, with their
+// names in every language and a base rate in MDL around which the rate moves.
+var syntheticCurrencies = []syntheticCurrency{
+	{"47", 978, "EUR", 1, map[string]string{bnm.LANG_EN: "Euro", bnm.LANG_RO: "Euro", bnm.LANG_RU: "Евро"}, 19.4},
+	{"44", 840, "USD", 1, map[string]string{bnm.LANG_EN: "US Dollar", bnm.LANG_RO: "Dolar S.U.A.", bnm.LANG_RU: "Доллар США"}, 17.9},
+	{"36", 643, "RUB", 1, map[string]string{bnm.LANG_EN: "Russian Ruble", bnm.LANG_RO: "Rubla rusească", bnm.LANG_RU: "Российский рубль"}, 0.19},
+	{"35", 946, "RON", 1, map[string]string{bnm.LANG_EN: "Romanian Leu", bnm.LANG_RO: "Leu românesc", bnm.LANG_RU: "Румынский лей"}, 3.9},
+	{"42", 980, "UAH", 1, map[string]string{bnm.LANG_EN: "Ukrainian Hryvnia", bnm.LANG_RO: "Grivna ucraineană", bnm.LANG_RU: "Украинская гривна"}, 0.43},
+	{"43", 826, "GBP", 1, map[string]string{bnm.LANG_EN: "Pound Sterling", bnm.LANG_RO: "Lira sterlină", bnm.LANG_RU: "Фунт стерлингов"}, 22.6},
+	{"41", 756, "CHF", 1, map[string]string{bnm.LANG_EN: "Swiss Franc", bnm.LANG_RO: "Franc elveţian", bnm.LANG_RU: "Швейцарский франк"}, 20.2},
+	{"39", 949, "TRY", 1, map[string]string{bnm.LANG_EN: "Turkish Lira", bnm.LANG_RO: "Lira turcească", bnm.LANG_RU: "Турецкая лира"}, 0.51},
+	{"29", 392, "JPY", 100, map[string]string{bnm.LANG_EN: "Japanese Yen", bnm.LANG_RO: "Yeni japonez", bnm.LANG_RU: "Японская иена"}, 11.6},
+	{"30", 156, "CNY", 1, map[string]string{bnm.LANG_EN: "Yuan Renminbi", bnm.LANG_RO: "Yuan Renminbi", bnm.LANG_RU: "Китайский юань"}, 2.46},
+}
+
+// Synthetic generates a plausible exchange rates document for the day of date
+// in the given language. Rates move smoothly over the year with a little daily
+// noise, and are deterministic for a given seed, currency and day.
+func Synthetic(date time.Time, lang string, seed int64) bnm.Response {
+	day := bnm.NewQuery(date, lang).Day()
+	days := float64(day.Unix()) / (24 * 60 * 60)
+
+	res := bnm.Response{
+		Date:       day.Format("02.01.2006"),
+		Name:       documentNames[lang],
+		Currencies: make([]bnm.Currency, 0, len(syntheticCurrencies)),
+	}
+	for i, c := range syntheticCurrencies {
+		trend := 0.03 * math.Sin(2*math.Pi*days/365+float64(i))
+		noise := (noise(seed, c.code, res.Date) - 0.5) * 0.004
+		value := math.Round(c.base*(1+trend+noise)*10000) / 10000
+
+		res.Currencies = append(res.Currencies, bnm.Currency{
+			ID:      c.id,
+			Code:    c.code,
+			NumCode: c.numCode,
+			Nominal: c.nominal,
+			Name:    c.names[lang],
+			Value:   float32(value),
+		})
+	}
+
+	return res
+}
+
+// noise returns a deterministic pseudo-random number in [0, 1).
+func noise(seed int64, parts ...string) float64 {
+	h := fnv.New64a()
+	fmt.Fprint(h, seed)
+	for _, p := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+// EncodeXML encodes a response as a BNM exchange rates document.
+func EncodeXML(res bnm.Response) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(&buf, "<ValCurs Date=\"%s\" name=\"%s\">\n", escape(res.Date), escape(res.Name))
+	for _, c := range res.Currencies {
+		fmt.Fprintf(&buf, "<Valute ID=\"%s\">\n", escape(c.ID))
+		fmt.Fprintf(&buf, "<NumCode>%03d</NumCode>\n", c.NumCode)
+		fmt.Fprintf(&buf, "<CharCode>%s</CharCode>\n", escape(c.Code))
+		fmt.Fprintf(&buf, "<Nominal>%d</Nominal>\n", c.Nominal)
+		fmt.Fprintf(&buf, "<Name>%s</Name>\n", escape(c.Name))
+		fmt.Fprintf(&buf, "<Value>%.4f</Value>\n", c.Value)
+		buf.WriteString("</Valute>\n")
+	}
+	buf.WriteString("</ValCurs>\n")
+
+	return buf.Bytes()
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
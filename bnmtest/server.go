@@ -0,0 +1,231 @@
+package bnmtest
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/calendar"
+)
+
+// xmlContentType is the content type of exchange rates documents.
+const xmlContentType = "text/xml; charset=utf-8"
+
+// fixtures are synthetic documents in the format of the BNM API, laid out as
+// <lang>/<YYYY-MM-DD>.xml.
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures returns the sample documents bundled with the package, laid out as
+// <lang>/<YYYY-MM-DD>.xml. They are generated with Synthetic (seed 2025) and
+// EncodeXML, not captured from BNM: use WithFixtures to serve real documents.
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithFixtures adds the documents of fsys, laid out as <lang>/<YYYY-MM-DD>.xml,
+// to the bundled ones. Files that do not follow the layout are ignored.
+func WithFixtures(fsys fs.FS) ServerOption {
+	return func(s *Server) { s.fixtureFS = append(s.fixtureFS, fsys) }
+}
+
+// WithSeed sets the seed of the synthetic rates. It defaults to 0.
+func WithSeed(seed int64) ServerOption {
+	return func(s *Server) { s.seed = seed }
+}
+
+// WithCalendar sets the calendar deciding which day's rates are served for
+// weekends and holidays. It defaults to calendar.Default.
+func WithCalendar(cal *calendar.Calendar) ServerOption {
+	return func(s *Server) { s.calendar = cal }
+}
+
+// Server is a fake BNM API. Like the real one, it answers requests for
+// weekends and holidays with the rates of the previous publication day.
+// Documents come from fixtures when available and are synthesized otherwise.
+// It is safe for concurrent use by multiple goroutines.
+type Server struct {
+	// URL is the base URL of the server, to be used with bnm.WithBaseURL.
+	URL string
+
+	srv       *httptest.Server
+	fixtureFS []fs.FS
+	seed      int64
+	calendar  *calendar.Calendar
+
+	mu       sync.Mutex
+	bodies   map[string][]byte
+	next     []Fault
+	always   Fault
+	requests []string
+}
+
+// NewServer starts a Server. It must be closed with Close.
+func NewServer(opts ...ServerOption) (*Server, error) {
+	s := &Server{
+		fixtureFS: []fs.FS{Fixtures()},
+		calendar:  calendar.Default,
+		bodies:    make(map[string][]byte),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, fsys := range s.fixtureFS {
+		if err := s.loadFixtures(fsys); err != nil {
+			return nil, fmt.Errorf("load fixtures: %w", err)
+		}
+	}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s, nil
+}
+
+// Start starts a Server closed at the end of the test, failing the test on error.
+func Start(tb testing.TB, opts ...ServerOption) *Server {
+	tb.Helper()
+
+	s, err := NewServer(opts...)
+	if err != nil {
+		tb.Fatalf("bnmtest: %v", err)
+	}
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// NewClient starts a Server closed at the end of the test and returns a
+// Client pointed at it, configured with the additional options.
+func NewClient(tb testing.TB, opts ...bnm.Option) (*bnm.Client, *Server) {
+	tb.Helper()
+
+	s := Start(tb)
+	return s.Client(opts...), s
+}
+
+// Client returns a Client pointed at the server, configured with the additional options.
+func (s *Server) Client(opts ...bnm.Option) *bnm.Client {
+	return bnm.NewClient(append([]bnm.Option{
+		bnm.WithBaseURL(s.URL),
+		bnm.WithHTTPClient(s.srv.Client()),
+	}, opts...)...)
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetFixture serves body for requests whose rates are those of date in lang.
+func (s *Server) SetFixture(date time.Time, lang string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies[fixtureKey(date, lang)] = body
+}
+
+// Inject makes the next requests fail, one Fault per request, in order.
+func (s *Server) Inject(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next = append(s.next, faults...)
+}
+
+// InjectAlways makes every request fail with the Fault once the faults queued
+// with Inject are used up. A nil Fault restores normal service.
+func (s *Server) InjectAlways(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.always = fault
+}
+
+// Requests returns the request URIs received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) loadFixtures(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		dir, name := path.Split(p)
+		lang := strings.TrimSuffix(dir, "/")
+		date, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(name, ".xml"), bnm.Location())
+		if err != nil || path.Ext(name) != ".xml" || lang == "" || strings.Contains(lang, "/") {
+			return nil
+		}
+
+		body, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		s.bodies[fixtureKey(date, lang)] = body
+		return nil
+	})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	fault := s.always
+	if len(s.next) > 0 {
+		fault, s.next = s.next[0], s.next[1:]
+	}
+	s.mu.Unlock()
+
+	lang, ok := strings.CutSuffix(strings.Trim(r.URL.Path, "/"), "/official_exchange_rates")
+	if !ok || documentNames[lang] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	date, err := time.ParseInLocation("02.01.2006", r.URL.Query().Get("date"), bnm.Location())
+	if err != nil {
+		http.Error(w, "invalid date", http.StatusBadRequest)
+		return
+	}
+
+	body := s.document(s.calendar.EffectiveDay(date), lang)
+	if fault != nil && fault(w, r, body) {
+		return
+	}
+
+	w.Header().Set("Content-Type", xmlContentType)
+	w.Write(body)
+}
+
+// document returns the fixture for the day, or synthesizes one.
+func (s *Server) document(day time.Time, lang string) []byte {
+	s.mu.Lock()
+	body, ok := s.bodies[fixtureKey(day, lang)]
+	s.mu.Unlock()
+	if ok {
+		return body
+	}
+
+	return EncodeXML(Synthetic(day, lang, s.seed))
+}
+
+func fixtureKey(date time.Time, lang string) string {
+	return lang + "/" + bnm.NewQuery(date, lang).Day().Format("2006-01-02")
+}
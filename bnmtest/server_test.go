@@ -0,0 +1,166 @@
+package bnmtest_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+func TestServer_Fixtures(t *testing.T) {
+	client, server := bnmtest.NewClient(t)
+
+	res, err := client.Fetch(t.Context(), bnm.NewQueryForDay(2025, time.January, 6, bnm.LANG_RU))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := fs.ReadFile(bnmtest.Fixtures(), "ru/2025-01-06.xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, err := bnm.NewClient(bnm.WithGetRequest(func(context.Context, string) ([]byte, error) {
+		return body, nil
+	})).Fetch(t.Context(), bnm.NewQueryForDay(2025, time.January, 6, bnm.LANG_RU))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	eur, _ := res.FindByCode("EUR")
+	if wantEUR, _ := want.FindByCode("EUR"); eur != wantEUR || eur.Name != "Евро" {
+		t.Errorf("expected fixture currency %+v, got %+v", wantEUR, eur)
+	}
+	if len(server.Requests()) != 1 {
+		t.Errorf("expected 1 request, got %v", server.Requests())
+	}
+}
+
+func TestServer_Weekend(t *testing.T) {
+	client, _ := bnmtest.NewClient(t)
+
+	res, err := client.Fetch(t.Context(), bnm.NewQueryForDay(2025, time.January, 12, bnm.LANG_EN))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Date != "10.01.2025" {
+		t.Errorf("expected the rates of Friday, got %s", res.Date)
+	}
+}
+
+func TestServer_Synthetic(t *testing.T) {
+	server := bnmtest.Start(t, bnmtest.WithSeed(42))
+	client := server.Client()
+	query := bnm.NewQueryForDay(2019, time.March, 12, bnm.LANG_RO)
+
+	res, err := client.Fetch(t.Context(), query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := bnmtest.Synthetic(query.Date, bnm.LANG_RO, 42)
+	if res.Date != want.Date || len(res.Currencies) != len(want.Currencies) {
+		t.Fatalf("expected %+v, got %+v", want, res)
+	}
+	for i := range want.Currencies {
+		if res.Currencies[i] != want.Currencies[i] {
+			t.Errorf("expected %+v, got %+v", want.Currencies[i], res.Currencies[i])
+		}
+	}
+}
+
+func TestServer_SetFixture(t *testing.T) {
+	client, server := bnmtest.NewClient(t)
+	date := time.Date(2020, 6, 2, 0, 0, 0, 0, bnm.Location())
+
+	res := bnmtest.Synthetic(date, bnm.LANG_EN, 0)
+	res.Currencies = res.Currencies[:1]
+	server.SetFixture(date, bnm.LANG_EN, bnmtest.EncodeXML(res))
+
+	got, err := client.Fetch(t.Context(), bnm.NewQuery(date, bnm.LANG_EN))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Currencies) != 1 {
+		t.Errorf("expected the fixture, got %+v", got)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   bnmtest.Fault
+		opts    []bnm.Option
+		wantErr error
+	}{
+		{"server error", bnmtest.ServerError(http.StatusBadGateway), nil, nil},
+		{"too many requests", bnmtest.TooManyRequests(time.Second), nil, nil},
+		{"truncated XML", bnmtest.TruncatedXML(), nil, nil},
+		{"HTML error page", bnmtest.HTMLErrorPage(), nil, bnm.ErrUnexpectedContent},
+		{"empty payload", bnmtest.EmptyPayload(), nil, bnm.ErrUnexpectedContent},
+		{"latency", bnmtest.Latency(time.Second), []bnm.Option{bnm.WithMiddleware(bnm.TimeoutMiddleware(10 * time.Millisecond))}, context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := bnmtest.NewClient(t, tt.opts...)
+			server.Inject(tt.fault)
+
+			query := bnm.NewQueryForDay(2025, time.January, 6, bnm.LANG_EN)
+			_, err := client.Fetch(t.Context(), query)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			// The fault only affects one request.
+			if _, err := client.Fetch(t.Context(), query); err != nil {
+				t.Errorf("unexpected error after fault: %v", err)
+			}
+		})
+	}
+}
+
+func TestServer_TooManyRequestsRetryAfter(t *testing.T) {
+	server := bnmtest.Start(t)
+	server.InjectAlways(bnmtest.TooManyRequests(1500 * time.Millisecond))
+
+	resp, err := http.Get(server.URL + "/en/official_exchange_rates?get_xml=1&date=06.01.2025")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("unexpected answer %d with Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
+
+func TestServer_InjectRecoveredByRetry(t *testing.T) {
+	client, server := bnmtest.NewClient(t, bnm.WithMiddleware(bnm.RetryMiddleware(3, time.Millisecond)))
+	server.Inject(bnmtest.ServerError(http.StatusServiceUnavailable), bnmtest.TooManyRequests(0))
+
+	if _, err := client.Fetch(t.Context(), bnm.NewQueryForDay(2025, time.January, 6, bnm.LANG_EN)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestServer_UnknownPath(t *testing.T) {
+	server := bnmtest.Start(t)
+
+	resp, err := http.Get(server.URL + "/de/official_exchange_rates?get_xml=1&date=06.01.2025")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
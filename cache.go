@@ -14,3 +14,17 @@ type Cache interface {
 	// It returns ErrNotFound if the key does not exist.
 	Get(ctx context.Context, key string) (Response, error)
 }
+
+// MetaCache is a Cache that also stores the Meta of a response, so that
+// cache hits still report the original provenance.
+type MetaCache interface {
+	Cache
+
+	// SetWithMeta stores a Response and its Meta in the cache associated with the given key.
+	// It returns an error if the operation fails.
+	SetWithMeta(ctx context.Context, key string, res Response, meta Meta) error
+
+	// GetWithMeta retrieves a Response and its Meta from the cache by its key.
+	// It returns ErrNotFound if the key does not exist.
+	GetWithMeta(ctx context.Context, key string) (Response, Meta, error)
+}
@@ -0,0 +1,247 @@
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+// Run runs the conformance suite against the caches returned by newCache,
+// which is called once per subtest and must return an empty cache able to
+// hold at least 100 entries.
+//
+// The suite checks ErrNotFound semantics, overwrites, key isolation, concurrent
+// use, context cancellation and the round trip of every Response field.
+// Caches implementing bnm.MetaCache are checked to round trip every Meta field.
+func Run(t *testing.T, newCache func() bnm.Cache) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(*testing.T, bnm.Cache)
+	}{
+		{"NotFound", testNotFound},
+		{"RoundTrip", testRoundTrip},
+		{"Overwrite", testOverwrite},
+		{"KeyIsolation", testKeyIsolation},
+		{"Concurrent", testConcurrent},
+		{"ContextCanceled", testContextCanceled},
+		{"MetaRoundTrip", testMetaRoundTrip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newCache())
+		})
+	}
+}
+
+// fullResponse returns a response with every field set, including non-ASCII names.
+func fullResponse() bnm.Response {
+	return bnm.Response{
+		Date: "06.01.2025",
+		Name: "Официальный курс",
+		Currencies: []bnm.Currency{
+			{ID: "47", Code: "EUR", NumCode: 978, Nominal: 1, Name: "Евро", Value: 19.4856},
+			{ID: "35", Code: "RON", NumCode: 946, Nominal: 1, Name: "Leu românesc", Value: 3.9123},
+			{ID: "29", Code: "JPY", NumCode: 392, Nominal: 100, Name: "Yeni japonez", Value: 11.6001},
+		},
+	}
+}
+
+func testNotFound(t *testing.T, cache bnm.Cache) {
+	_, err := cache.Get(t.Context(), "en_06.01.2025")
+	if !errors.Is(err, bnm.ErrNotFound) {
+		t.Errorf("Get() of a missing key: error = %v, want %v", err, bnm.ErrNotFound)
+	}
+}
+
+func testRoundTrip(t *testing.T, cache bnm.Cache) {
+	want := fullResponse()
+	if err := cache.Set(t.Context(), "ru_06.01.2025", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := cache.Get(t.Context(), "ru_06.01.2025")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+}
+
+func testOverwrite(t *testing.T, cache bnm.Cache) {
+	first := fullResponse()
+	second := fullResponse()
+	second.Currencies = second.Currencies[:1]
+	second.Currencies[0].Value = 20.1
+
+	for _, res := range []bnm.Response{first, second} {
+		if err := cache.Set(t.Context(), "en_06.01.2025", res); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	got, err := cache.Get(t.Context(), "en_06.01.2025")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, second) {
+		t.Errorf("Get() after overwrite = %+v, want %+v", got, second)
+	}
+}
+
+func testKeyIsolation(t *testing.T, cache bnm.Cache) {
+	keys := []string{"en_06.01.2025", "ro_06.01.2025", "en_07.01.2025", "en_06.01.20250"}
+	for i, key := range keys {
+		res := fullResponse()
+		res.Name = key
+		res.Currencies[0].Value = float32(i)
+		if err := cache.Set(t.Context(), key, res); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+
+	for i, key := range keys {
+		got, err := cache.Get(t.Context(), key)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", key, err)
+		}
+		if got.Name != key || got.Currencies[0].Value != float32(i) {
+			t.Errorf("Get(%q) returned the entry of another key: %+v", key, got)
+		}
+	}
+
+	if _, err := cache.Get(t.Context(), "en_06.01"); !errors.Is(err, bnm.ErrNotFound) {
+		t.Errorf("Get() of a key prefix: error = %v, want %v", err, bnm.ErrNotFound)
+	}
+}
+
+func testConcurrent(t *testing.T, cache bnm.Cache) {
+	const goroutines, ops, keys = 16, 50, 20
+
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ops {
+				key := fmt.Sprintf("en_%02d.01.2025", (g+i)%keys+1)
+				res := fullResponse()
+				res.Name = key
+				if err := cache.Set(t.Context(), key, res); err != nil {
+					t.Errorf("Set(%q) error = %v", key, err)
+					return
+				}
+
+				got, err := cache.Get(t.Context(), key)
+				if err != nil && !errors.Is(err, bnm.ErrNotFound) {
+					t.Errorf("Get(%q) error = %v", key, err)
+					return
+				}
+				if err == nil && got.Name != key {
+					t.Errorf("Get(%q) returned the entry of another key: %q", key, got.Name)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testContextCanceled checks that a canceled context makes operations either
+// proceed as usual or fail promptly with an error wrapping context.Canceled.
+func testContextCanceled(t *testing.T, cache bnm.Cache) {
+	if err := cache.Set(t.Context(), "en_06.01.2025", fullResponse()); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	// The operations run in a goroutine that may outlive the test if they hang,
+	// so their results are checked here rather than there.
+	type result struct {
+		setErr error
+		got    bnm.Response
+		getErr error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		r.setErr = cache.Set(ctx, "en_07.01.2025", fullResponse())
+		r.got, r.getErr = cache.Get(ctx, "en_06.01.2025")
+		done <- r
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("operations with a canceled context did not return")
+	}
+
+	if r.setErr != nil && !errors.Is(r.setErr, context.Canceled) {
+		t.Errorf("Set() with a canceled context: error = %v, want nil or %v", r.setErr, context.Canceled)
+	}
+	switch {
+	case errors.Is(r.getErr, context.Canceled):
+	case r.getErr != nil:
+		t.Errorf("Get() with a canceled context: error = %v, want nil or %v", r.getErr, context.Canceled)
+	case !reflect.DeepEqual(r.got, fullResponse()):
+		t.Errorf("Get() with a canceled context = %+v, want %+v", r.got, fullResponse())
+	}
+}
+
+func testMetaRoundTrip(t *testing.T, cache bnm.Cache) {
+	mc, ok := cache.(bnm.MetaCache)
+	if !ok {
+		t.Skip("cache does not implement bnm.MetaCache")
+	}
+
+	chisinau := bnm.Location()
+	want := bnm.Meta{
+		RequestedDate: time.Date(2025, 1, 5, 0, 0, 0, 0, chisinau),
+		EffectiveDate: time.Date(2025, 1, 3, 0, 0, 0, 0, chisinau),
+		SourceURL:     "https://www.bnm.md/ru/official_exchange_rates?get_xml=1&date=05.01.2025",
+		FetchedAt:     time.Date(2025, 1, 5, 10, 30, 15, 0, time.UTC),
+		Cache:         bnm.CacheMiss,
+		HTTPStatus:    200,
+		SHA256:        "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Attempts:      2,
+		ETag:          `"5e8f-62b1"`,
+		LastModified:  "Sun, 05 Jan 2025 10:30:15 GMT",
+	}
+	if err := mc.SetWithMeta(t.Context(), "ru_05.01.2025", fullResponse(), want); err != nil {
+		t.Fatalf("SetWithMeta() error = %v", err)
+	}
+
+	res, got, err := mc.GetWithMeta(t.Context(), "ru_05.01.2025")
+	if err != nil {
+		t.Fatalf("GetWithMeta() error = %v", err)
+	}
+	if !reflect.DeepEqual(res, fullResponse()) {
+		t.Errorf("GetWithMeta() response = %+v, want %+v", res, fullResponse())
+	}
+
+	// Times may come back in another location, e.g. after serialization.
+	if !got.RequestedDate.Equal(want.RequestedDate) || !got.EffectiveDate.Equal(want.EffectiveDate) || !got.FetchedAt.Equal(want.FetchedAt) {
+		t.Errorf("GetWithMeta() times = %v, %v, %v, want %v, %v, %v",
+			got.RequestedDate, got.EffectiveDate, got.FetchedAt, want.RequestedDate, want.EffectiveDate, want.FetchedAt)
+	}
+	got.RequestedDate, got.EffectiveDate, got.FetchedAt = want.RequestedDate, want.EffectiveDate, want.FetchedAt
+	if got != want {
+		t.Errorf("GetWithMeta() meta = %+v, want %+v", got, want)
+	}
+
+	if _, _, err := mc.GetWithMeta(t.Context(), "ru_06.01.2025"); !errors.Is(err, bnm.ErrNotFound) {
+		t.Errorf("GetWithMeta() of a missing key: error = %v, want %v", err, bnm.ErrNotFound)
+	}
+}
@@ -0,0 +1,13 @@
+// Package cachetest provides a conformance test suite for bnm.Cache implementations.
+//
+// A custom cache, e.g. backed by Redis, SQL or files, is tested with:
+//
+//	func TestRedisCache(t *testing.T) {
+//	    cachetest.Run(t, func() bnm.Cache {
+//	        return newRedisCache(t)
+//	    })
+//	}
+//
+// The suite should be run with -race to check that the cache is safe for
+// concurrent use.
+package cachetest
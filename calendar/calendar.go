@@ -0,0 +1,221 @@
+package calendar
+
+import (
+	"sync"
+	"time"
+)
+
+// Holiday is a public holiday.
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// fixedHoliday is a holiday falling on the same date every year.
+type fixedHoliday struct {
+	month time.Month
+	day   int
+	name  string
+	since int
+}
+
+// fixedHolidays are the fixed-date public holidays of the Republic of Moldova.
+var fixedHolidays = []fixedHoliday{
+	{time.January, 1, "New Year's Day", 0},
+	{time.January, 7, "Orthodox Christmas", 0},
+	{time.January, 8, "Orthodox Christmas", 0},
+	{time.March, 8, "International Women's Day", 0},
+	{time.May, 1, "Labour Day", 0},
+	{time.May, 9, "Victory and Europe Day", 0},
+	{time.June, 1, "Children's Day", 2016},
+	{time.August, 27, "Independence Day", 0},
+	{time.August, 31, "National Language Day", 0},
+	{time.December, 25, "Christmas", 2013},
+}
+
+// civilDate is a calendar date without time or location.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func toCivil(t time.Time) civilDate {
+	y, m, d := t.Date()
+	return civilDate{y, m, d}
+}
+
+// Calendar is a public holiday calendar with per-date overrides.
+// It is safe for concurrent use by multiple goroutines.
+type Calendar struct {
+	mu       sync.RWMutex
+	added    map[civilDate]string
+	removed  map[civilDate]bool
+	workdays map[civilDate]bool
+}
+
+// Moldova creates a Calendar with the public holidays of the Republic of Moldova:
+// the fixed-date holidays, Orthodox Easter Sunday and Monday, and Memorial
+// Easter (the second Monday after Orthodox Easter).
+func Moldova() *Calendar {
+	return &Calendar{
+		added:    make(map[civilDate]string),
+		removed:  make(map[civilDate]bool),
+		workdays: make(map[civilDate]bool),
+	}
+}
+
+// AddHoliday declares date a public holiday, e.g. a day off decreed by the government.
+func (c *Calendar) AddHoliday(date time.Time, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d := toCivil(date)
+	c.added[d] = name
+	delete(c.removed, d)
+	delete(c.workdays, d)
+}
+
+// RemoveHoliday declares that date is not a public holiday.
+func (c *Calendar) RemoveHoliday(date time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d := toCivil(date)
+	c.removed[d] = true
+	delete(c.added, d)
+}
+
+// AddWorkday declares date a working day even if it falls on a weekend,
+// e.g. a Saturday worked to compensate a transferred day off.
+func (c *Calendar) AddWorkday(date time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d := toCivil(date)
+	c.workdays[d] = true
+	delete(c.added, d)
+}
+
+// Holidays returns the public holidays of the year in chronological order,
+// at midnight UTC.
+func (c *Calendar) Holidays(year int) []Holiday {
+	var holidays []Holiday
+	for d := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); d.Year() == year; d = d.AddDate(0, 0, 1) {
+		if name, ok := c.Holiday(d); ok {
+			holidays = append(holidays, Holiday{Date: d, Name: name})
+		}
+	}
+
+	return holidays
+}
+
+// Holiday returns the name of the public holiday falling on date, if any.
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	d := toCivil(date)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if name, ok := c.added[d]; ok {
+		return name, true
+	}
+	if c.removed[d] || c.workdays[d] {
+		return "", false
+	}
+
+	return builtinHoliday(d)
+}
+
+// IsPublicationDay reports whether BNM publishes rates of its own for date:
+// a weekday that is not a public holiday, or a declared working day.
+func (c *Calendar) IsPublicationDay(date time.Time) bool {
+	d := toCivil(date)
+
+	c.mu.RLock()
+	workday := c.workdays[d]
+	c.mu.RUnlock()
+	if workday {
+		return true
+	}
+
+	if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+
+	_, holiday := c.Holiday(date)
+	return !holiday
+}
+
+// PreviousPublicationDay returns the last publication day strictly before date.
+func (c *Calendar) PreviousPublicationDay(date time.Time) time.Time {
+	d := midnight(date).AddDate(0, 0, -1)
+	for !c.IsPublicationDay(d) {
+		d = d.AddDate(0, 0, -1)
+	}
+
+	return d
+}
+
+// NextPublicationDay returns the first publication day strictly after date.
+func (c *Calendar) NextPublicationDay(date time.Time) time.Time {
+	d := midnight(date).AddDate(0, 0, 1)
+	for !c.IsPublicationDay(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+
+	return d
+}
+
+// EffectiveDay returns date itself if it is a publication day, or the previous
+// publication day whose rates are in force on date otherwise.
+func (c *Calendar) EffectiveDay(date time.Time) time.Time {
+	if c.IsPublicationDay(date) {
+		return midnight(date)
+	}
+
+	return c.PreviousPublicationDay(date)
+}
+
+func builtinHoliday(d civilDate) (string, bool) {
+	for _, h := range fixedHolidays {
+		if h.month == d.month && h.day == d.day && d.year >= h.since {
+			return h.name, true
+		}
+	}
+
+	easter := OrthodoxEaster(d.year)
+	date := time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC)
+	switch date.Sub(easter) / (24 * time.Hour) {
+	case 0:
+		return "Orthodox Easter", true
+	case 1:
+		return "Orthodox Easter Monday", true
+	case 8:
+		return "Memorial Easter", true
+	}
+
+	return "", false
+}
+
+// OrthodoxEaster returns the date of Orthodox Easter Sunday of the year in the
+// Gregorian calendar, at midnight UTC. It is valid for the years 1900 to 2099.
+func OrthodoxEaster(year int) time.Time {
+	// Meeus' Julian algorithm.
+	a := year % 4
+	b := year % 7
+	c := year % 19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+
+	// Shift from the Julian to the Gregorian calendar.
+	shift := year/100 - year/400 - 2
+	return time.Date(year, time.Month(month), day+shift, 0, 0, 0, 0, time.UTC)
+}
+
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,115 @@
+package calendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2/calendar"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOrthodoxEaster(t *testing.T) {
+	tests := []struct {
+		year int
+		want time.Time
+	}{
+		{2017, date(2017, time.April, 16)},
+		{2021, date(2021, time.May, 2)},
+		{2023, date(2023, time.April, 16)},
+		{2024, date(2024, time.May, 5)},
+		{2025, date(2025, time.April, 20)},
+		{2026, date(2026, time.April, 12)},
+	}
+
+	for _, tt := range tests {
+		if got := calendar.OrthodoxEaster(tt.year); !got.Equal(tt.want) {
+			t.Errorf("OrthodoxEaster(%d) = %v, want %v", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestCalendar_Holiday(t *testing.T) {
+	cal := calendar.Moldova()
+
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{date(2025, time.January, 1), "New Year's Day"},
+		{date(2025, time.January, 8), "Orthodox Christmas"},
+		{date(2025, time.April, 21), "Orthodox Easter Monday"},
+		{date(2025, time.April, 28), "Memorial Easter"},
+		{date(2025, time.August, 27), "Independence Day"},
+		{date(2012, time.December, 25), ""},
+		{date(2025, time.December, 25), "Christmas"},
+		{date(2025, time.April, 22), ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := cal.Holiday(tt.date)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Holiday(%s) = %q, %v, want %q", tt.date.Format("2006-01-02"), got, ok, tt.want)
+		}
+	}
+}
+
+func TestCalendar_Holidays(t *testing.T) {
+	holidays := calendar.Moldova().Holidays(2025)
+	if len(holidays) != 13 {
+		t.Errorf("expected 13 holidays in 2025, got %d: %v", len(holidays), holidays)
+	}
+}
+
+func TestCalendar_PublicationDays(t *testing.T) {
+	cal := calendar.Moldova()
+
+	// Easter Monday 21 April 2025 follows a weekend.
+	easterMonday := date(2025, time.April, 21)
+	if cal.IsPublicationDay(easterMonday) {
+		t.Error("expected Easter Monday not to be a publication day")
+	}
+	if got := cal.PreviousPublicationDay(easterMonday); !got.Equal(date(2025, time.April, 18)) {
+		t.Errorf("PreviousPublicationDay() = %v, want Friday 18 April", got)
+	}
+	if got := cal.NextPublicationDay(date(2025, time.April, 18)); !got.Equal(date(2025, time.April, 22)) {
+		t.Errorf("NextPublicationDay() = %v, want Tuesday 22 April", got)
+	}
+	if got := cal.EffectiveDay(date(2025, time.April, 20)); !got.Equal(date(2025, time.April, 18)) {
+		t.Errorf("EffectiveDay() = %v, want Friday 18 April", got)
+	}
+
+	// The location of the input is preserved.
+	loc := time.FixedZone("EET", 2*60*60)
+	if got := cal.NextPublicationDay(time.Date(2025, time.April, 18, 23, 30, 0, 0, loc)); got.Location() != loc || got.Day() != 22 {
+		t.Errorf("NextPublicationDay() = %v", got)
+	}
+}
+
+func TestCalendar_Overrides(t *testing.T) {
+	cal := calendar.Moldova()
+
+	decreed := date(2025, time.May, 2)
+	cal.AddHoliday(decreed, "Transferred day off")
+	if cal.IsPublicationDay(decreed) {
+		t.Error("expected added holiday not to be a publication day")
+	}
+
+	worked := date(2025, time.May, 10)
+	cal.AddWorkday(worked)
+	if !cal.IsPublicationDay(worked) {
+		t.Error("expected declared working Saturday to be a publication day")
+	}
+
+	womensDay := date(2024, time.March, 8)
+	cal.RemoveHoliday(womensDay)
+	if !cal.IsPublicationDay(womensDay) {
+		t.Error("expected removed holiday to be a publication day")
+	}
+
+	if !calendar.Moldova().IsPublicationDay(date(2025, time.May, 2)) {
+		t.Error("expected overrides not to leak into other calendars")
+	}
+}
@@ -0,0 +1,31 @@
+package calendar
+
+import "time"
+
+// Default is the Moldova calendar used by the package-level functions.
+// Overrides applied to it are seen by all its users.
+var Default = Moldova()
+
+// IsPublicationDay reports whether BNM publishes rates of its own for date,
+// according to the Default calendar.
+func IsPublicationDay(date time.Time) bool {
+	return Default.IsPublicationDay(date)
+}
+
+// PreviousPublicationDay returns the last publication day strictly before date,
+// according to the Default calendar.
+func PreviousPublicationDay(date time.Time) time.Time {
+	return Default.PreviousPublicationDay(date)
+}
+
+// NextPublicationDay returns the first publication day strictly after date,
+// according to the Default calendar.
+func NextPublicationDay(date time.Time) time.Time {
+	return Default.NextPublicationDay(date)
+}
+
+// EffectiveDay returns the publication day whose rates are in force on date,
+// according to the Default calendar.
+func EffectiveDay(date time.Time) time.Time {
+	return Default.EffectiveDay(date)
+}
@@ -0,0 +1,8 @@
+// Package calendar provides the Moldovan public holiday calendar and tells
+// which dates have their own official BNM exchange rates.
+//
+// BNM publishes official rates on business days; for weekends and public
+// holidays the rates of the previous publication day stay in force.
+// All functions work on the calendar date of the given time.Time in its own
+// location and return dates at midnight in that same location.
+package calendar
@@ -0,0 +1,238 @@
+package bnm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/OsoianMarcel/bnm-go/v2/internal/atomicfile"
+)
+
+// CassetteModeEnv is the environment variable overriding the mode of every
+// cassette, e.g. BNM_CASSETTE_MODE=record go test ./... to re-record them all.
+// Its values are "replay", "record" and "auto".
+const CassetteModeEnv = "BNM_CASSETTE_MODE"
+
+// ErrNotRecorded is returned in replay mode for requests missing from the cassette.
+var ErrNotRecorded = errors.New("request not recorded in cassette")
+
+// CassetteMode tells whether a Cassette records or replays upstream requests.
+type CassetteMode int
+
+const (
+	// CassetteReplay serves requests from the cassette file and fails with
+	// ErrNotRecorded for any request missing from it. Nothing is sent upstream.
+	CassetteReplay CassetteMode = iota
+
+	// CassetteRecord sends requests upstream and records them, replacing the
+	// previous content of the cassette file.
+	CassetteRecord
+
+	// CassetteAuto replays the cassette file if it exists and records it otherwise.
+	CassetteAuto
+)
+
+// String returns the name of the mode, as used in CassetteModeEnv.
+func (m CassetteMode) String() string {
+	switch m {
+	case CassetteReplay:
+		return "replay"
+	case CassetteRecord:
+		return "record"
+	case CassetteAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("CassetteMode(%d)", int(m))
+	}
+}
+
+// cassetteInteraction is a recorded upstream request.
+// Bodies that are not valid UTF-8, e.g. in a legacy encoding, are base64-encoded.
+type cassetteInteraction struct {
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"body_base64,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// Cassette records upstream requests to a JSON file and replays them, so that
+// integration tests run once against the real BNM API can be repeated offline.
+// It is safe for concurrent use by multiple goroutines.
+type Cassette struct {
+	path string
+	mode CassetteMode
+
+	mu           sync.Mutex
+	interactions []cassetteInteraction
+	played       map[string]int
+}
+
+// NewCassette opens the cassette file at path in the given mode, unless
+// overridden by CassetteModeEnv. In replay mode, the file must exist.
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	if env := os.Getenv(CassetteModeEnv); env != "" {
+		var err error
+		if mode, err = parseCassetteMode(env); err != nil {
+			return nil, fmt.Errorf("%s: %w", CassetteModeEnv, err)
+		}
+	}
+
+	if mode == CassetteAuto {
+		mode = CassetteReplay
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			mode = CassetteRecord
+		}
+	}
+
+	c := &Cassette{path: path, mode: mode, played: make(map[string]int)}
+	if mode == CassetteRecord {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	c.interactions = file.Interactions
+
+	return c, nil
+}
+
+func parseCassetteMode(s string) (CassetteMode, error) {
+	for _, mode := range []CassetteMode{CassetteReplay, CassetteRecord, CassetteAuto} {
+		if strings.EqualFold(s, mode.String()) {
+			return mode, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown cassette mode %q", s)
+}
+
+// Mode returns the effective mode of the cassette: CassetteReplay or CassetteRecord.
+func (c *Cassette) Mode() CassetteMode {
+	return c.mode
+}
+
+// Middleware returns a Middleware recording or replaying requests.
+// In record mode the cassette file is rewritten after every request.
+//
+// Requests canceled by the caller are not recorded. Requests for a URL are
+// replayed in the order they were recorded; once they are used up, the last
+// one is repeated. Upstream errors are replayed with their message only, so
+// errors.Is no longer matches their original cause; StatusErrors keep their
+// status code but not their RetryAfter delay.
+func (c *Cassette) Middleware() Middleware {
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			if c.mode == CassetteRecord {
+				return c.record(ctx, next, url)
+			}
+			return c.replay(ctx, url)
+		}
+	}
+}
+
+func (c *Cassette) record(ctx context.Context, next GetRequestFunc, url string) ([]byte, error) {
+	body, err := next(ctx, url)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		// The caller gave up on the request, e.g. a losing hedge: replaying
+		// the cancellation would fail requests that succeeded.
+		return body, err
+	}
+
+	in := cassetteInteraction{URL: url}
+	var serr *StatusError
+	if errors.As(err, &serr) {
+		in.Status = serr.StatusCode
+	} else if tr := requestTraceFromContext(ctx); tr != nil && err == nil {
+		_, in.Status, _, _, _ = tr.result()
+	}
+	if err != nil {
+		in.Error = err.Error()
+	} else if utf8.Valid(body) {
+		in.Body = string(body)
+	} else {
+		in.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, in)
+	data, merr := json.MarshalIndent(cassetteFile{c.interactions}, "", "  ")
+	if merr == nil {
+		merr = atomicfile.Write(c.path, data)
+	}
+	if merr != nil {
+		return nil, fmt.Errorf("record cassette: %w", merr)
+	}
+
+	return body, err
+}
+
+func (c *Cassette) replay(ctx context.Context, url string) ([]byte, error) {
+	c.mu.Lock()
+	var matches []cassetteInteraction
+	for _, in := range c.interactions {
+		if in.URL == url {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is missing from %s; record it with %s=record", ErrNotRecorded, url, c.path, CassetteModeEnv)
+	}
+	in := matches[min(c.played[url], len(matches)-1)]
+	c.played[url]++
+	c.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if in.Error != "" {
+		if in.Status != 0 {
+			return nil, &StatusError{StatusCode: in.Status}
+		}
+		return nil, errors.New(in.Error)
+	}
+	if tr := requestTraceFromContext(ctx); tr != nil && in.Status != 0 {
+		tr.setResponse(in.Status, "", "")
+	}
+	if in.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(in.BodyBase64)
+	}
+
+	return []byte(in.Body), nil
+}
+
+// WithCassette records or replays upstream requests with the cassette file at
+// path, see NewCassette. The cassette replaces the GetRequestFunc in replay
+// mode and wraps it in record mode; it sits below all middlewares, so every
+// retry is recorded. Conditional requests are disabled. An unreadable cassette
+// makes every Fetch call fail.
+func WithCassette(path string, mode CassetteMode) Option {
+	return func(c *Client) {
+		cassette, err := NewCassette(path, mode)
+		if err != nil {
+			c.err = fmt.Errorf("cassette: %w", err)
+			return
+		}
+		c.cassette = cassette
+	}
+}
@@ -0,0 +1,153 @@
+package bnm_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+func TestCassette_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	server := bnmtest.Start(t)
+	server.Inject(bnmtest.ServerError(http.StatusBadGateway))
+
+	monday := bnm.NewQueryForDay(2025, time.January, 6, bnm.LANG_EN)
+	tuesday := bnm.NewQueryForDay(2025, time.January, 7, bnm.LANG_RU)
+	retry := bnm.WithMiddleware(bnm.RetryMiddleware(2, time.Millisecond))
+
+	recorder := server.Client(bnm.WithCassette(path, bnm.CassetteRecord), retry)
+	want := make(map[string]bnm.Response)
+	for _, q := range []bnm.Query{monday, tuesday} {
+		res, err := recorder.Fetch(t.Context(), q)
+		if err != nil {
+			t.Fatalf("record: unexpected error: %v", err)
+		}
+		want[q.ID()] = res
+	}
+	server.Close()
+
+	player := bnm.NewClient(bnm.WithBaseURL(server.URL), bnm.WithCassette(path, bnm.CassetteReplay), retry)
+	for _, q := range []bnm.Query{monday, tuesday} {
+		res, meta, err := player.FetchWithMeta(t.Context(), q)
+		if err != nil {
+			t.Fatalf("replay: unexpected error: %v", err)
+		}
+		if res.Date != want[q.ID()].Date || len(res.Currencies) != len(want[q.ID()].Currencies) {
+			t.Errorf("replay: expected %+v, got %+v", want[q.ID()], res)
+		}
+		if meta.HTTPStatus != http.StatusOK {
+			t.Errorf("replay: expected status 200, got %d", meta.HTTPStatus)
+		}
+		// The failed first attempt for Monday is replayed too.
+		if wantAttempts := map[string]int{monday.ID(): 2, tuesday.ID(): 1}[q.ID()]; meta.Attempts != wantAttempts {
+			t.Errorf("replay %s: expected %d attempts, got %d", q.ID(), wantAttempts, meta.Attempts)
+		}
+	}
+
+	_, err := player.Fetch(t.Context(), bnm.NewQueryForDay(2025, time.January, 8, bnm.LANG_EN))
+	if !errors.Is(err, bnm.ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded, got %v", err)
+	}
+}
+
+func TestCassette_BinaryBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	body := []byte("<?xml version=\"1.0\" encoding=\"windows-1251\"?><ValCurs><Name>\xc5\xe2\xf0\xee</Name></ValCurs>")
+
+	recorder := bnm.NewClient(
+		bnm.WithCassette(path, bnm.CassetteRecord),
+		bnm.WithGetRequest(func(context.Context, string) ([]byte, error) { return body, nil }),
+	)
+	if _, _, err := recorder.FetchRaw(t.Context(), dummyQuery()); err != nil {
+		t.Fatalf("record: unexpected error: %v", err)
+	}
+
+	player := bnm.NewClient(bnm.WithCassette(path, bnm.CassetteReplay))
+	got, _, err := player.FetchRaw(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("replay: unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("replay: expected %q, got %q", body, got)
+	}
+}
+
+func TestCassette_RecordHedged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	// recorded receives a value once a request has gone through the cassette.
+	var calls atomic.Int32
+	canceled := make(chan struct{}, 1)
+	recorded := make(chan struct{}, 2)
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 6, 10, 0, 0, 0, bnm.Location()))
+	recorder := bnm.NewClient(
+		bnm.WithClock(clock),
+		bnm.WithCassette(path, bnm.CassetteRecord),
+		bnm.WithMiddleware(
+			bnm.HedgingMiddleware(time.Second, 1),
+			func(next bnm.GetRequestFunc) bnm.GetRequestFunc {
+				return func(ctx context.Context, url string) ([]byte, error) {
+					defer func() { recorded <- struct{}{} }()
+					return next(ctx, url)
+				}
+			},
+		),
+		bnm.WithGetRequest(stallFirst(&calls, canceled)),
+	)
+
+	done := fetchRaw(t.Context(), recorder)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("record: unexpected error: %v", err)
+	}
+	<-canceled
+	<-recorded
+	<-recorded
+
+	// The canceled request is not replayed, not even once the hedge is used up.
+	player := bnm.NewClient(bnm.WithCassette(path, bnm.CassetteReplay))
+	for range 2 {
+		body, _, err := player.FetchRaw(t.Context(), dummyQuery())
+		if err != nil || string(body) != "ok" {
+			t.Fatalf("replay: expected %q, got %q, %v", "ok", body, err)
+		}
+	}
+}
+
+func TestCassette_Modes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	if _, err := bnm.NewCassette(path, bnm.CassetteReplay); err == nil {
+		t.Error("expected an error replaying a missing cassette")
+	}
+
+	cassette, err := bnm.NewCassette(path, bnm.CassetteAuto)
+	if err != nil || cassette.Mode() != bnm.CassetteRecord {
+		t.Fatalf("expected auto mode to record a missing cassette, got %v, %v", cassette, err)
+	}
+
+	t.Setenv(bnm.CassetteModeEnv, "record")
+	cassette, err = bnm.NewCassette(path, bnm.CassetteReplay)
+	if err != nil || cassette.Mode() != bnm.CassetteRecord {
+		t.Errorf("expected the environment to override the mode, got %v, %v", cassette, err)
+	}
+
+	t.Setenv(bnm.CassetteModeEnv, "rewind")
+	if _, err := bnm.NewCassette(path, bnm.CassetteRecord); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+
+	client := bnm.NewClient(bnm.WithCassette(path, bnm.CassetteRecord))
+	if _, err := client.Fetch(t.Context(), dummyQuery()); err == nil {
+		t.Error("expected an invalid cassette option to make Fetch fail")
+	}
+}
@@ -0,0 +1,88 @@
+package bnm
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUnmarshalResponse_Charsets(t *testing.T) {
+	tests := []struct {
+		encoded string
+		utf8    string
+	}{
+		{"ru-2025-01-06.windows-1251.xml", "ru/2025-01-06.xml"},
+		{"ro-2025-01-06.windows-1250.xml", "ro/2025-01-06.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoded, func(t *testing.T) {
+			encoded, err := os.ReadFile(filepath.Join("testdata", "charset", tt.encoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			plain, err := os.ReadFile(filepath.Join("bnmtest", "fixtures", tt.utf8))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := unmarshalResponse(plain)
+			if err != nil {
+				t.Fatalf("unexpected error for UTF-8 fixture: %v", err)
+			}
+			got, err := unmarshalResponse(encoded)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResponse_UnsupportedCharset(t *testing.T) {
+	data := `<?xml version="1.0" encoding="koi8-r"?><ValCurs Date="05.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><Value>1</Value></Valute></ValCurs>`
+
+	if _, err := unmarshalResponse([]byte(data)); !errors.Is(err, ErrUnexpectedContent) || !strings.Contains(err.Error(), "koi8-r") {
+		t.Errorf("expected ErrUnexpectedContent naming the charset, got %v", err)
+	}
+}
+
+func TestCharsetReader(t *testing.T) {
+	tests := []struct {
+		label string
+		in    string
+		want  string
+	}{
+		{"windows-1251", "\xc5\xe2\xf0\xee \xb8\xa8", "Евро ёЁ"},
+		{"CP1251", "\x98", "�"},
+		{"windows-1250", "Leu rom\xe2nesc, elve\xfeian, ruseasc\xe3", "Leu românesc, elveţian, rusească"},
+		{" x-cp1250 ", "\x81\x8a", "�Š"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			r, err := charsetReader(tt.label, iotest.OneByteReader(strings.NewReader(tt.in)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(iotest.OneByteReader(r))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := charsetReader("utf-16", strings.NewReader("")); err == nil {
+		t.Error("expected an error for an unsupported charset")
+	}
+}
@@ -0,0 +1,238 @@
+package bnm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through while counting consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with ErrCircuitOpen until the cool-down elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker.
+// Zero values are replaced with the defaults.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit. Defaults to 5.
+	FailureThreshold int
+
+	// CoolDown is how long the circuit stays open before probing. Defaults to 30 seconds.
+	CoolDown time.Duration
+
+	// HalfOpenProbes is the number of probe requests allowed while half-open;
+	// that many consecutive successes close the circuit. Defaults to 1.
+	HalfOpenProbes int
+
+	// OnStateChange, if set, is called after every state transition.
+	OnStateChange func(from, to CircuitState)
+
+	// Clock measures the cool-down. Defaults to the system clock.
+	Clock Clock
+}
+
+// CircuitBreaker stops sending requests to a failing upstream for a while,
+// failing fast with ErrCircuitOpen instead.
+// It is safe for concurrent use by multiple goroutines.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker.
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+
+	return &CircuitBreaker{cfg: cfg}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.cfg.Clock.Now().Sub(b.openedAt) >= b.cfg.CoolDown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request may be sent, reserving a probe when half-open,
+// and returns the state transition it caused.
+func (b *CircuitBreaker) allow() (allowed bool, from, to CircuitState) {
+	b.mu.Lock()
+	from = b.state
+
+	if b.state == CircuitOpen && b.cfg.Clock.Now().Sub(b.openedAt) >= b.cfg.CoolDown {
+		b.setState(CircuitHalfOpen)
+	}
+
+	allowed = true
+	switch b.state {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			allowed = false
+		} else {
+			b.probes++
+		}
+	}
+	to = b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return allowed, from, to
+}
+
+// record updates the circuit with the result of an allowed request and
+// returns the state transition it caused.
+func (b *CircuitBreaker) record(err error) (from, to CircuitState) {
+	b.mu.Lock()
+	from = b.state
+
+	switch {
+	case err == nil && b.state == CircuitHalfOpen:
+		b.probes--
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenProbes {
+			b.setState(CircuitClosed)
+		}
+	case err == nil:
+		b.failures = 0
+	case b.state == CircuitHalfOpen:
+		b.setState(CircuitOpen)
+	case b.state == CircuitClosed:
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.setState(CircuitOpen)
+		}
+	}
+	to = b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return from, to
+}
+
+// release gives back a reserved probe without recording a result.
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// setState switches the state and resets the counters.
+// Must be called with mutex held.
+func (b *CircuitBreaker) setState(s CircuitState) {
+	b.state = s
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+	if s == CircuitOpen {
+		b.openedAt = b.cfg.Clock.Now()
+	}
+}
+
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
+
+// CircuitBreakerMiddleware returns a Middleware that rejects requests with
+// ErrCircuitOpen while the circuit is open. Failures caused by the caller
+// canceling the context are not counted, while successful answers whose body
+// cannot be an exchange rates document, such as an empty body or an HTML
+// maintenance page, count as failures.
+func CircuitBreakerMiddleware(b *CircuitBreaker) Middleware {
+	return circuitBreakerMiddleware(b, nil)
+}
+
+// circuitBreakerMiddleware works like CircuitBreakerMiddleware and also
+// reports the state transitions caused by its requests to onChange, if not nil.
+func circuitBreakerMiddleware(b *CircuitBreaker, onChange func(from, to CircuitState)) Middleware {
+	changed := func(from, to CircuitState) {
+		if from != to && onChange != nil {
+			onChange(from, to)
+		}
+	}
+
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			allowed, from, to := b.allow()
+			changed(from, to)
+			if !allowed {
+				return nil, ErrCircuitOpen
+			}
+
+			body, err := next(ctx, url)
+			if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				b.release()
+				return body, err
+			}
+
+			result := err
+			if result == nil && !notModified(ctx) {
+				result = checkPayload(body)
+			}
+			changed(b.record(result))
+			return body, err
+		}
+	}
+}
+
+// notModified reports whether the request traced by the context was answered
+// with 304 Not Modified, whose body is empty.
+func notModified(ctx context.Context) bool {
+	tr := requestTraceFromContext(ctx)
+	if tr == nil {
+		return false
+	}
+
+	notModified, _, _, _, _ := tr.result()
+	return notModified
+}
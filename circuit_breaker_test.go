@@ -0,0 +1,222 @@
+package bnm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	var transitions []string
+	clock := bnmtest.NewFakeClock(time.Now())
+	b := bnm.NewCircuitBreaker(bnm.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		CoolDown:         time.Minute,
+		Clock:            clock,
+		OnStateChange: func(from, to bnm.CircuitState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+
+	fail := true
+	calls := 0
+	fn := bnm.CircuitBreakerMiddleware(b)(func(_ context.Context, _ string) ([]byte, error) {
+		calls++
+		if fail {
+			return nil, errors.New("upstream down")
+		}
+		return []byte("ok"), nil
+	})
+
+	for range 2 {
+		fn(t.Context(), "http://example.com")
+	}
+	if b.State() != bnm.CircuitOpen {
+		t.Fatalf("expected open circuit, got %v", b.State())
+	}
+
+	if _, err := fn(t.Context(), "http://example.com"); !errors.Is(err, bnm.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected open circuit to fail fast, got %d calls", calls)
+	}
+
+	// A failed probe opens the circuit again.
+	clock.Advance(time.Minute)
+	fn(t.Context(), "http://example.com")
+	if b.State() != bnm.CircuitOpen {
+		t.Fatalf("expected open circuit after failed probe, got %v", b.State())
+	}
+
+	// A successful probe closes it.
+	clock.Advance(time.Minute)
+	fail = false
+	if _, err := fn(t.Context(), "http://example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.State() != bnm.CircuitClosed {
+		t.Fatalf("expected closed circuit, got %v", b.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("want transitions %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("want transitions %v, got %v", want, transitions)
+			break
+		}
+	}
+}
+
+func TestCircuitBreaker_CanceledNotCounted(t *testing.T) {
+	b := bnm.NewCircuitBreaker(bnm.CircuitBreakerConfig{FailureThreshold: 1})
+	fn := bnm.CircuitBreakerMiddleware(b)(func(ctx context.Context, _ string) ([]byte, error) {
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	fn(ctx, "http://example.com")
+
+	if b.State() != bnm.CircuitClosed {
+		t.Errorf("expected closed circuit, got %v", b.State())
+	}
+}
+
+func TestCircuitBreaker_UnexpectedContentCounted(t *testing.T) {
+	for _, body := range []string{"", "<!DOCTYPE html><html><body>Site under maintenance</body></html>"} {
+		b := bnm.NewCircuitBreaker(bnm.CircuitBreakerConfig{FailureThreshold: 1})
+		fn := bnm.CircuitBreakerMiddleware(b)(func(_ context.Context, _ string) ([]byte, error) {
+			return []byte(body), nil
+		})
+
+		if _, err := fn(t.Context(), "http://example.com"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b.State() != bnm.CircuitOpen {
+			t.Errorf("body %q: expected open circuit, got %v", body, b.State())
+		}
+	}
+}
+
+func TestWithCircuitBreaker_StateChangesWarned(t *testing.T) {
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, bnm.Location()))
+	var warnings []error
+	fail := true
+	client := bnm.NewClient(
+		bnm.WithClock(clock),
+		bnm.WithCircuitBreaker(bnm.NewCircuitBreaker(bnm.CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute, Clock: clock}), nil),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			if fail {
+				return []byte("<html><body>Site under maintenance</body></html>"), nil
+			}
+			return []byte(`<ValCurs Date="01.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`), nil
+		}),
+		bnm.WithWarnError(func(err error) { warnings = append(warnings, err) }),
+	)
+
+	if _, err := client.Fetch(t.Context(), dummyQuery()); !errors.Is(err, bnm.ErrUnexpectedContent) {
+		t.Fatalf("expected ErrUnexpectedContent, got %v", err)
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], bnm.ErrCircuitOpen) {
+		t.Fatalf("expected the opening to be warned, got %v", warnings)
+	}
+
+	clock.Advance(time.Minute)
+	fail = false
+	if _, err := client.Fetch(t.Context(), dummyQuery()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("expected the half-open and closed transitions to be warned, got %v", warnings)
+	}
+	for i, want := range []string{"open to half-open", "half-open to closed"} {
+		if msg := warnings[i+1].Error(); !strings.Contains(msg, want) {
+			t.Errorf("warning %q does not mention %q", msg, want)
+		}
+	}
+}
+
+func TestWithCircuitBreaker_Fallback(t *testing.T) {
+	fallback, _ := bnm.NewMemoryCache(10)
+	fail := false
+	client := bnm.NewClient(
+		bnm.WithCircuitBreaker(bnm.NewCircuitBreaker(bnm.CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Hour}), fallback),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			if fail {
+				return nil, errors.New("upstream down")
+			}
+			return []byte("<ValCurs/>"), nil
+		}),
+		bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+			return dummyResponse(), nil
+		}),
+	)
+
+	if _, err := client.Fetch(t.Context(), dummyQuery()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fail = true
+	if _, err := client.Fetch(t.Context(), dummyQuery()); err == nil || errors.Is(err, bnm.ErrCircuitOpen) {
+		t.Fatalf("expected upstream error, got %v", err)
+	}
+
+	resp, err := client.Fetch(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("expected fallback response, got error %v", err)
+	}
+	if resp.Date != dummyResponse().Date {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	other := bnm.NewQuery(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+	if _, err := client.Fetch(t.Context(), other); !errors.Is(err, bnm.ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+// mapCache is a Cache of a non-comparable type.
+type mapCache map[string]bnm.Response
+
+func (m mapCache) Get(_ context.Context, key string) (bnm.Response, error) {
+	res, ok := m[key]
+	if !ok {
+		return bnm.Response{}, bnm.ErrNotFound
+	}
+	return res, nil
+}
+
+func (m mapCache) Set(_ context.Context, key string, res bnm.Response) error {
+	m[key] = res
+	return nil
+}
+
+func TestWithCircuitBreaker_MapCacheAsFallback(t *testing.T) {
+	cache := mapCache{}
+	client := bnm.NewClient(
+		bnm.WithCache(cache),
+		bnm.WithCircuitBreaker(bnm.NewCircuitBreaker(bnm.CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Hour}), cache),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			return []byte("<ValCurs/>"), nil
+		}),
+		bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+			return dummyResponse(), nil
+		}),
+	)
+
+	if _, err := client.Fetch(t.Context(), dummyQuery()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache[dummyQuery().ID()]; !ok {
+		t.Errorf("response not stored in the cache")
+	}
+}
@@ -2,9 +2,26 @@ package bnm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2/calendar"
 )
 
+// DefaultBaseURL is the BNM API endpoint used when no base URL is configured.
+const DefaultBaseURL = "https://www.bnm.md"
+
+// DefaultRevalidateAfter is how long cached responses for the current day are
+// served before being revalidated, unless overridden with WithRevalidateAfter.
+const DefaultRevalidateAfter = time.Minute
+
 // Option configures a Client.
 type Option func(*Client)
 
@@ -21,14 +38,34 @@ type WarnFunc func(error)
 
 // Client is used to fetch exchange rates from the National Bank of Moldova (BNM) API.
 type Client struct {
-	cache       Cache
-	getRequest  GetRequestFunc
-	unmarshaler UnmarshalerFunc
-	warnError   WarnFunc
+	baseURL         string
+	cache           Cache
+	http            httpGetter
+	getRequest      GetRequestFunc
+	middlewares     []Middleware
+	limiter         *RateLimiter
+	hedging         Middleware
+	breaker         *CircuitBreaker
+	fallback        Cache
+	storeFallback   bool
+	datePolicy      DatePolicy
+	archiver        Archiver
+	cassette        *Cassette
+	unmarshaler     UnmarshalerFunc
+	warnError       WarnFunc
+	clock           Clock
+	revalidateAfter time.Duration
+
+	calendar       *calendar.Calendar
+	maxLookBack    int
+	effectiveDates *lru[time.Time]
+
+	// err holds an invalid option reported by Fetch.
+	err error
 }
 
 // NewClient creates a new Client instance with optional configuration.
-// By default, it uses the standard HTTP client and the default unmarshaler.
+// By default, it uses an HTTP client with a 30 second timeout and the default unmarshaler.
 //
 // Example:
 //
@@ -38,32 +75,242 @@ type Client struct {
 //	)
 func NewClient(opts ...Option) *Client {
 	c := &Client{
-		getRequest:  getRequestWithDefaultClient,
-		unmarshaler: unmarshalResponse,
+		baseURL:         DefaultBaseURL,
+		http:            newHTTPGetter(),
+		clock:           systemClock{},
+		revalidateAfter: DefaultRevalidateAfter,
+
+		calendar:       calendar.Default,
+		maxLookBack:    DefaultMaxLookBack,
+		effectiveDates: newLRU[time.Time](effectiveDatesCapacity),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	if c.unmarshaler == nil {
+		// Documents are limited to the body size set by WithMaxBodySize.
+		maxSize := c.http.maxBodySize
+		if maxSize <= 0 {
+			maxSize = -1
+		}
+		c.unmarshaler = NewUnmarshaler(DecodeOptions{MaxSize: maxSize})
+	}
+	// Responses are stored in the fallback unless it is the cache itself.
+	c.storeFallback = c.fallback != nil && !sameCache(c.fallback, c.cache)
+	if c.getRequest == nil {
+		c.getRequest = c.http.get
+	}
+	if c.cassette != nil {
+		c.getRequest = c.cassette.Middleware()(c.getRequest)
+		c.revalidateAfter = -1
+	}
+	c.getRequest = traceAttempts(c.getRequest)
+	if c.limiter != nil {
+		c.getRequest = RateLimitMiddleware(c.limiter)(c.getRequest)
+	}
+	if c.hedging != nil {
+		c.getRequest = c.hedging(c.getRequest)
+	}
+	c.getRequest = chainMiddleware(c.getRequest, c.middlewares...)
+	if c.breaker != nil {
+		c.getRequest = circuitBreakerMiddleware(c.breaker, func(from, to CircuitState) {
+			err := fmt.Errorf("circuit breaker: %s to %s", from, to)
+			if to == CircuitOpen {
+				err = fmt.Errorf("%w: %s to %s", ErrCircuitOpen, from, to)
+			}
+			c.warn(err)
+		})(c.getRequest)
+	}
+
 	return c
 }
 
+// WithBaseURL sets the base URL of the BNM API endpoint, e.g. an internal mirror
+// or a local stand-in used in integration tests. It defaults to DefaultBaseURL.
+//
+// The URL must be absolute, use the http or https scheme and must not contain
+// a query or fragment. An invalid URL makes every Fetch call fail.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if err := validateBaseURL(baseURL); err != nil {
+			c.err = fmt.Errorf("base url %q: %w", baseURL, err)
+			return
+		}
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithRevalidateAfter sets how long cached responses for the current or a
+// future day are served before being revalidated. Past rates never change and
+// are always served from the cache.
+//
+// Revalidation uses the ETag and Last-Modified validators stored in the Meta of
+// the cached response, so it requires a MetaCache. The default GetRequestFunc
+// makes the request conditional, and a 304 Not Modified answer refreshes the
+// cached response. A negative duration disables revalidation.
+// It defaults to DefaultRevalidateAfter.
+func WithRevalidateAfter(d time.Duration) Option {
+	return func(c *Client) { c.revalidateAfter = d }
+}
+
 // WithCache sets a Cache implementation on the Client.
 func WithCache(cache Cache) Option {
 	return func(c *Client) { c.cache = cache }
 }
 
+// WithHTTPClient sets the HTTP client used by the default GetRequestFunc,
+// e.g. to configure timeouts, proxies or TLS. A nil client is invalid.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		if client == nil {
+			c.err = errors.New("http client: nil")
+			return
+		}
+		c.http.client = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent by the default GetRequestFunc.
+// It defaults to DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.http.userAgent = userAgent }
+}
+
+// WithMaxBodySize sets the maximum response body size in bytes accepted by the
+// default GetRequestFunc and the default unmarshaler. Larger bodies fail with ErrBodyTooLarge.
+// A non-positive size disables the limit. It defaults to DefaultMaxBodySize.
+func WithMaxBodySize(size int64) Option {
+	return func(c *Client) { c.http.maxBodySize = size }
+}
+
+// WithRequestHeaders adds headers sent by the default GetRequestFunc.
+// It can be used multiple times; the headers are merged.
+func WithRequestHeaders(headers http.Header) Option {
+	return func(c *Client) {
+		if c.http.headers == nil {
+			c.http.headers = make(http.Header, len(headers))
+		}
+		for key, values := range headers {
+			for _, v := range values {
+				c.http.headers.Add(key, v)
+			}
+		}
+	}
+}
+
 // WithGetRequest sets a custom GetRequestFunc on the Client.
+// It replaces the default HTTP implementation, so WithHTTPClient, WithUserAgent,
+// WithMaxBodySize and WithRequestHeaders have no effect.
 func WithGetRequest(fn GetRequestFunc) Option {
 	return func(c *Client) { c.getRequest = fn }
 }
 
+// WithMiddleware adds middlewares wrapping the default or custom GetRequestFunc.
+// It can be used multiple times. The first middleware added is the outermost one:
+// it is called first and sees the result of all the others.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *Client) { c.middlewares = append(c.middlewares, mws...) }
+}
+
+// WithRateLimit limits upstream requests to rps requests per second on average
+// with bursts of up to burst requests. Cache hits are not counted.
+// Invalid values make every Fetch call fail.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		l, err := NewRateLimiter(rps, burst)
+		if err != nil {
+			c.err = fmt.Errorf("rate limit: %w", err)
+			return
+		}
+		c.limiter = l
+	}
+}
+
+// WithRateLimiter limits upstream requests with the given RateLimiter, which
+// may be shared with other clients. Cache hits are not counted.
+// The limiter is applied closest to the request, so every retry is limited too.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) { c.limiter = l }
+}
+
+// WithHedging sends a second upstream request when the first one has not
+// completed after delay, using whichever succeeds first and canceling the other.
+// budget caps hedged requests to that ratio of all requests, in the range (0, 1].
+// Both requests are subject to the rate limit, if any. Invalid values make every
+// Fetch call fail.
+func WithHedging(delay time.Duration, budget float64) Option {
+	return func(c *Client) {
+		if err := validateHedging(delay, budget); err != nil {
+			c.err = fmt.Errorf("hedging: %w", err)
+			return
+		}
+		c.hedging = HedgingMiddleware(delay, budget)
+	}
+}
+
+// WithCircuitBreaker protects the upstream with the given CircuitBreaker, which
+// may be shared with other clients. The breaker wraps all middlewares, so a
+// request retried by a middleware counts as a single failure.
+//
+// State changes caused by the client's requests are reported to the WarnFunc,
+// with an error wrapping ErrCircuitOpen when the circuit opens.
+// While the circuit is open, Fetch fails fast with an error wrapping
+// ErrCircuitOpen, unless fallback is not nil and holds the query's response.
+// Successfully fetched responses are stored in the fallback as well, which is
+// typically a longer-lived cache than the one set with WithCache.
+func WithCircuitBreaker(b *CircuitBreaker, fallback Cache) Option {
+	return func(c *Client) {
+		c.breaker = b
+		c.fallback = fallback
+	}
+}
+
+// WithDatePolicy sets how Fetch handles responses whose date differs from the
+// requested one. It defaults to DateAccept.
+func WithDatePolicy(p DatePolicy) Option {
+	return func(c *Client) { c.datePolicy = p }
+}
+
+// WithCalendar sets the publication calendar used by EffectiveRate.
+// It defaults to calendar.Default.
+func WithCalendar(cal *calendar.Calendar) Option {
+	return func(c *Client) { c.calendar = cal }
+}
+
+// WithMaxLookBack sets the maximum number of days EffectiveRate walks back
+// from the requested date to find published rates. A non-positive value makes
+// every Fetch call fail. It defaults to DefaultMaxLookBack.
+func WithMaxLookBack(days int) Option {
+	return func(c *Client) {
+		if days <= 0 {
+			c.err = fmt.Errorf("max look-back: %d days must be positive", days)
+			return
+		}
+		c.maxLookBack = days
+	}
+}
+
+// WithArchiver sets an Archiver called with the raw body of every upstream fetch,
+// including FetchRaw calls. Archiving failures are reported to the WarnFunc.
+func WithArchiver(a Archiver) Option {
+	return func(c *Client) { c.archiver = a }
+}
+
 // WithUnmarshaler sets a custom UnmarshalerFunc on the Client.
 func WithUnmarshaler(u UnmarshalerFunc) Option {
 	return func(c *Client) { c.unmarshaler = u }
 }
 
+// WithClock sets the Clock used for fetch times and for Today. It is passed to
+// the middlewares of this package through the request context, so that retry
+// backoff, hedging delays, rate limit waits and logged durations follow it too.
+// It defaults to the system clock.
+func WithClock(clock Clock) Option {
+	return func(c *Client) { c.clock = clock }
+}
+
 // WithWarnError sets a WarnFunc on the Client to log non-critical errors.
 func WithWarnError(fn WarnFunc) Option {
 	return func(c *Client) { c.warnError = fn }
@@ -74,35 +321,282 @@ func WithWarnError(fn WarnFunc) Option {
 // and finally stores the result in the cache.
 //
 // Returns an error if the request fails, if unmarshaling fails, or
-// if there is an unexpected cache error.
+// if there is an unexpected cache error. Payloads that are not exchange rate
+// documents fail with ErrUnexpectedContent and documents without rates with
+// ErrNoRatesPublished; neither is cached.
 //
 // Example:
 //
 //	resp, err := client.Fetch(ctx, NewQuery(time.Now(), bnm.LANG_EN)
 func (c *Client) Fetch(ctx context.Context, query Query) (Response, error) {
+	res, _, err := c.FetchWithMeta(ctx, query)
+	return res, err
+}
+
+// FetchWithMeta works like Fetch but also returns metadata describing where
+// the response came from: requested and effective dates, source URL, fetch time,
+// cache status, HTTP status, body hash and number of attempts. The metadata is
+// returned even on error, filled in as far as it is known.
+//
+// Caches implementing MetaCache store the metadata alongside the response, so
+// that cache hits report the original provenance. Other caches only report
+// the dates and the cache status.
+//
+// For weekends, holidays and future dates BNM returns the last available rates;
+// how such a date mismatch is handled depends on the DatePolicy set with WithDatePolicy.
+func (c *Client) FetchWithMeta(ctx context.Context, query Query) (Response, Meta, error) {
+	if c.err != nil {
+		return Response{}, Meta{RequestedDate: query.Day()}, c.err
+	}
+
+	var stale *cachedResponse
 	if c.cache != nil {
-		if cache, err := c.cache.Get(ctx, query.ID()); err == nil {
-			return cache, nil
+		if cache, meta, err := getCache(ctx, c.cache, query.ID()); err == nil {
+			meta.RequestedDate = query.Day()
+			meta.Cache = CacheHit
+			if !c.shouldRevalidate(meta) {
+				return cache, meta, nil
+			}
+			stale = &cachedResponse{res: cache, meta: meta}
 		} else if err != ErrNotFound {
-			return Response{}, fmt.Errorf("get cache: %w", err)
+			return Response{}, Meta{RequestedDate: query.Day()}, fmt.Errorf("get cache: %w", err)
 		}
 	}
 
-	data, err := c.getRequest(ctx, query.RequestURL())
+	res, meta, err := c.fetchUpstream(ctx, query, stale)
+	meta.RequestedDate = query.Day()
+	if err != nil && stale != nil && ctx.Err() == nil {
+		// Serve the cached response while the upstream is unavailable.
+		c.warn(fmt.Errorf("revalidate: %w", err))
+		return stale.res, stale.meta, nil
+	}
+	if errors.Is(err, ErrCircuitOpen) && c.fallback != nil {
+		if fallback, fmeta, ferr := getCache(ctx, c.fallback, query.ID()); ferr == nil {
+			fmeta.RequestedDate = query.Day()
+			fmeta.Cache = CacheFallback
+			return fallback, fmeta, nil
+		} else if ferr != ErrNotFound {
+			c.warn(fmt.Errorf("get fallback cache: %w", ferr))
+		}
+	}
 	if err != nil {
-		return Response{}, fmt.Errorf("get request: %w", err)
+		return Response{}, meta, err
+	}
+
+	effective, err := res.Time()
+	meta.EffectiveDate = effective
+
+	key := query.ID()
+	if c.datePolicy != DateAccept {
+		if err != nil {
+			return Response{}, meta, fmt.Errorf("parse date: %w", err)
+		}
+
+		if meta.DateMismatch() {
+			switch c.datePolicy {
+			case DateReject:
+				return Response{}, meta, fmt.Errorf("requested %s, got %s: %w", query.dateToStr(), res.Date, ErrDateMismatch)
+			case DateCacheEffective:
+				key = NewQuery(effective, query.Lang).ID()
+			}
+		}
+	}
+
+	if c.cache != nil {
+		if err := setCache(ctx, c.cache, key, res, meta); err != nil {
+			c.warn(fmt.Errorf("set cache: %w", err))
+		}
+	}
+
+	if c.storeFallback {
+		if err := setCache(ctx, c.fallback, key, res, meta); err != nil {
+			c.warn(fmt.Errorf("set fallback cache: %w", err))
+		}
+	}
+
+	return res, meta, nil
+}
+
+// FetchRaw retrieves the untouched response body for a query from the BNM API,
+// e.g. to retain the original documents. It bypasses the cache, never makes
+// conditional requests and does not parse the body. The body is archived
+// when an Archiver is configured.
+func (c *Client) FetchRaw(ctx context.Context, query Query) ([]byte, Meta, error) {
+	if c.err != nil {
+		return nil, Meta{RequestedDate: query.Day()}, c.err
+	}
+
+	return c.fetchBody(ctx, query, &requestTrace{})
+}
+
+// Today returns midnight of the current day in Europe/Chisinau according to the
+// Clock set with WithClock.
+func (c *Client) Today() time.Time {
+	return DayOf(c.clock.Now())
+}
+
+// RequestURL returns the URL used to request exchange rates for the query
+// from the configured BNM API endpoint.
+func (c *Client) RequestURL(query Query) string {
+	return c.baseURL + query.requestPath()
+}
+
+// sameCache reports whether a and b are the same cache. Caches of
+// non-comparable types, such as maps, are never considered the same, as
+// comparing them would panic.
+func sameCache(a, b Cache) bool {
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || t == nil || !t.Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return errors.New("scheme must be http or https")
+	case u.Host == "":
+		return errors.New("host is missing")
+	case u.RawQuery != "" || u.ForceQuery || u.Fragment != "":
+		return errors.New("query and fragment are not allowed")
+	}
+
+	return nil
+}
+
+// warn reports a non-critical error to the WarnFunc, if any.
+func (c *Client) warn(err error) {
+	if c.warnError != nil {
+		c.warnError(err)
+	}
+}
+
+// cachedResponse is a response found in the cache, together with its Meta.
+type cachedResponse struct {
+	res  Response
+	meta Meta
+}
+
+// shouldRevalidate reports whether a cached response must be revalidated
+// before being served.
+func (c *Client) shouldRevalidate(meta Meta) bool {
+	if c.revalidateAfter < 0 || (meta.ETag == "" && meta.LastModified == "") {
+		return false
+	}
+	if meta.RequestedDate.Before(c.Today()) {
+		return false
+	}
+
+	return !c.clock.Now().Before(meta.FetchedAt.Add(c.revalidateAfter))
+}
+
+// fetchUpstream requests and parses the response for the query. If stale is
+// not nil, the request is conditional on its validators and a 304 Not Modified
+// answer returns it again.
+func (c *Client) fetchUpstream(ctx context.Context, query Query, stale *cachedResponse) (Response, Meta, error) {
+	tr := &requestTrace{}
+	if stale != nil {
+		tr.etag, tr.lastModified = stale.meta.ETag, stale.meta.LastModified
+	}
+
+	data, meta, err := c.fetchBody(ctx, query, tr)
+	if err != nil {
+		return Response{}, meta, err
+	}
+
+	if meta.Cache == CacheRevalidated {
+		meta.SHA256 = stale.meta.SHA256
+		if meta.ETag == "" && meta.LastModified == "" {
+			meta.ETag, meta.LastModified = stale.meta.ETag, stale.meta.LastModified
+		}
+		return stale.res, meta, nil
 	}
 
 	res, err := c.unmarshaler(data)
+	var partial *PartialResponseError
+	if errors.As(err, &partial) && len(res.Currencies) > 0 {
+		c.warn(fmt.Errorf("parse body: %w", err))
+		err = nil
+	}
 	if err != nil {
-		return Response{}, fmt.Errorf("parse body: %w", err)
+		return Response{}, meta, fmt.Errorf("parse body: %w", err)
 	}
 
-	if c.cache != nil {
-		if err := c.cache.Set(ctx, query.ID(), res); err != nil {
-			c.warnError(fmt.Errorf("set cache: %w", err))
+	// Guard against custom unmarshalers so that empty responses are never cached.
+	if len(res.Currencies) == 0 {
+		return Response{}, meta, fmt.Errorf("parse body: %w", ErrNoRatesPublished)
+	}
+
+	return res, meta, nil
+}
+
+// fetchBody requests the raw body for the query and archives it.
+// A 304 Not Modified answer is reported with the CacheRevalidated status and an empty body.
+func (c *Client) fetchBody(ctx context.Context, query Query, tr *requestTrace) ([]byte, Meta, error) {
+	meta := Meta{
+		RequestedDate: query.Day(),
+		SourceURL:     c.RequestURL(query),
+		FetchedAt:     c.clock.Now(),
+		Cache:         CacheMiss,
+	}
+
+	data, err := c.getRequest(withClock(withRequestTrace(ctx, tr), c.clock), meta.SourceURL)
+	notModified, status, attempts, etag, lastModified := tr.result()
+	meta.HTTPStatus, meta.Attempts = status, attempts
+	meta.ETag, meta.LastModified = etag, lastModified
+	if err != nil {
+		return nil, meta, fmt.Errorf("get request: %w", err)
+	}
+
+	if notModified {
+		meta.Cache = CacheRevalidated
+		return data, meta, nil
+	}
+
+	sum := sha256.Sum256(data)
+	meta.SHA256 = hex.EncodeToString(sum[:])
+
+	if c.archiver != nil {
+		if err := c.archiver.Archive(ctx, query, data, meta); err != nil {
+			c.warn(fmt.Errorf("archive: %w", err))
 		}
 	}
 
-	return res, nil
+	return data, meta, nil
+}
+
+// getCache reads a response and, if the cache implements MetaCache, its metadata.
+func getCache(ctx context.Context, cache Cache, key string) (Response, Meta, error) {
+	var res Response
+	var meta Meta
+	var err error
+	if mc, ok := cache.(MetaCache); ok {
+		res, meta, err = mc.GetWithMeta(ctx, key)
+	} else {
+		res, err = cache.Get(ctx, key)
+	}
+	if err != nil {
+		return Response{}, Meta{}, err
+	}
+
+	if meta.EffectiveDate.IsZero() {
+		meta.EffectiveDate, _ = res.Time()
+	}
+	return res, meta, nil
+}
+
+// setCache stores a response and, if the cache implements MetaCache, its metadata.
+func setCache(ctx context.Context, cache Cache, key string, res Response, meta Meta) error {
+	if mc, ok := cache.(MetaCache); ok {
+		return mc.SetWithMeta(ctx, key, res, meta)
+	}
+
+	return cache.Set(ctx, key, res)
 }
@@ -2,11 +2,19 @@ package bnm_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
 )
 
 type mockCache struct {
@@ -25,6 +33,13 @@ func dummyQuery() bnm.Query {
 	return bnm.NewQuery(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
 }
 
+func dummyResponse() bnm.Response {
+	return bnm.Response{
+		Date:       "2025-01-01",
+		Currencies: []bnm.Currency{{Code: "EUR", Value: 19.5}},
+	}
+}
+
 func TestFetch_CacheHit(t *testing.T) {
 	cache := &mockCache{
 		getFunc: func(_ context.Context, _ string) (bnm.Response, error) {
@@ -57,7 +72,7 @@ func TestFetch_CacheMissThenFetch(t *testing.T) {
 			return []byte(`{"date":"2025-01-01"}`), nil
 		}),
 		bnm.WithUnmarshaler(func(b []byte) (bnm.Response, error) {
-			return bnm.Response{Date: "2025-01-01"}, nil
+			return dummyResponse(), nil
 		}),
 	)
 
@@ -70,6 +85,32 @@ func TestFetch_CacheMissThenFetch(t *testing.T) {
 	}
 }
 
+func TestFetch_EmptyResponseNotCached(t *testing.T) {
+	cache := &mockCache{
+		getFunc: func(_ context.Context, _ string) (bnm.Response, error) {
+			return bnm.Response{}, bnm.ErrNotFound
+		},
+		setFunc: func(_ context.Context, _ string, _ bnm.Response) error {
+			t.Error("unexpected cache set")
+			return nil
+		},
+	}
+	client := bnm.NewClient(
+		bnm.WithCache(cache),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			return nil, nil
+		}),
+		bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+			return bnm.Response{Date: "2025-01-01"}, nil
+		}),
+	)
+
+	_, err := client.Fetch(t.Context(), dummyQuery())
+	if !errors.Is(err, bnm.ErrNoRatesPublished) {
+		t.Fatalf("expected ErrNoRatesPublished, got %v", err)
+	}
+}
+
 func TestFetch_CacheError(t *testing.T) {
 	cache := &mockCache{
 		getFunc: func(_ context.Context, _ string) (bnm.Response, error) {
@@ -142,7 +183,7 @@ func TestFetch_CacheSetErrorWarns(t *testing.T) {
 			return []byte(`{"date":"2025-01-01"}`), nil
 		}),
 		bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
-			return bnm.Response{Date: "2025-01-01"}, nil
+			return dummyResponse(), nil
 		}),
 		bnm.WithWarnError(func(err error) {
 			warnCalled = true
@@ -160,3 +201,437 @@ func TestFetch_CacheSetErrorWarns(t *testing.T) {
 		t.Fatal("expected warnError to be called, but it wasn't")
 	}
 }
+
+func TestClient_RequestURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []bnm.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: "https://www.bnm.md/en/official_exchange_rates?get_xml=1&date=01.01.2025",
+		},
+		{
+			name: "mirror with path",
+			opts: []bnm.Option{bnm.WithBaseURL("http://mirror.internal:8080/bnm/")},
+			want: "http://mirror.internal:8080/bnm/en/official_exchange_rates?get_xml=1&date=01.01.2025",
+		},
+		{
+			name:    "unsupported scheme",
+			opts:    []bnm.Option{bnm.WithBaseURL("ftp://www.bnm.md")},
+			wantErr: true,
+		},
+		{
+			name:    "missing host",
+			opts:    []bnm.Option{bnm.WithBaseURL("/relative")},
+			wantErr: true,
+		},
+		{
+			name:    "query not allowed",
+			opts:    []bnm.Option{bnm.WithBaseURL("https://www.bnm.md?x=1")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotURL string
+			opts := append(tt.opts,
+				bnm.WithGetRequest(func(_ context.Context, url string) ([]byte, error) {
+					gotURL = url
+					return nil, nil
+				}),
+				bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+					return dummyResponse(), nil
+				}),
+			)
+
+			_, err := bnm.NewClient(opts...).Fetch(t.Context(), dummyQuery())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotURL != tt.want {
+				t.Errorf("want %q, got %q", tt.want, gotURL)
+			}
+		})
+	}
+}
+
+func TestClient_NilHTTPClient(t *testing.T) {
+	_, err := bnm.NewClient(bnm.WithHTTPClient(nil)).Fetch(t.Context(), dummyQuery())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestClient_HTTPOptions(t *testing.T) {
+	var gotUA, gotProxyAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		gotProxyAuth = r.Header.Get("X-Proxy-Auth")
+		fmt.Fprint(w, `<ValCurs Date="01.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`)
+	}))
+	defer ts.Close()
+
+	client := bnm.NewClient(
+		bnm.WithBaseURL(ts.URL),
+		bnm.WithHTTPClient(ts.Client()),
+		bnm.WithUserAgent("treasury/1.0"),
+		bnm.WithRequestHeaders(http.Header{"X-Proxy-Auth": {"token"}}),
+	)
+
+	resp, err := client.Fetch(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Date != "01.01.2025" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if gotUA != "treasury/1.0" || gotProxyAuth != "token" {
+		t.Errorf("unexpected headers: User-Agent=%q X-Proxy-Auth=%q", gotUA, gotProxyAuth)
+	}
+
+	_, err = bnm.NewClient(
+		bnm.WithBaseURL(ts.URL),
+		bnm.WithMaxBodySize(8),
+	).Fetch(t.Context(), dummyQuery())
+	if !errors.Is(err, bnm.ErrBodyTooLarge) {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestClient_UnlimitedBodySize(t *testing.T) {
+	// 12 MiB is above DefaultMaxBodySize.
+	body := `<ValCurs Date="01.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute>` +
+		strings.Repeat(" ", 12<<20) + `</ValCurs>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	_, err := bnm.NewClient(bnm.WithBaseURL(ts.URL), bnm.WithHTTPClient(ts.Client())).Fetch(t.Context(), dummyQuery())
+	if !errors.Is(err, bnm.ErrBodyTooLarge) {
+		t.Errorf("expected ErrBodyTooLarge with the default limit, got %v", err)
+	}
+
+	resp, err := bnm.NewClient(
+		bnm.WithBaseURL(ts.URL),
+		bnm.WithHTTPClient(ts.Client()),
+		bnm.WithMaxBodySize(-1),
+	).Fetch(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("unexpected error without body size limit: %v", err)
+	}
+	if eur, ok := resp.FindByCode("EUR"); !ok || eur.Value != 19.5 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestClient_FetchRevalidation(t *testing.T) {
+	var mu sync.Mutex
+	requests, notModified, fail := 0, 0, false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		switch {
+		case fail:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.Header.Get("If-None-Match") == `"v1"`:
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `<ValCurs Date="01.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`)
+		}
+	}))
+	defer ts.Close()
+
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, bnm.Location()))
+	cache, _ := bnm.NewMemoryCache(10, bnm.WithCacheClock(clock))
+	var warnings []error
+	client := bnm.NewClient(
+		bnm.WithBaseURL(ts.URL),
+		bnm.WithHTTPClient(ts.Client()),
+		bnm.WithCache(cache),
+		bnm.WithClock(clock),
+		bnm.WithWarnError(func(err error) { warnings = append(warnings, err) }),
+	)
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		fail        bool
+		status      bnm.CacheStatus
+		requests    int
+		revalidated int
+	}{
+		{"first fetch", 0, false, bnm.CacheMiss, 1, 0},
+		{"fresh cache hit", 30 * time.Second, false, bnm.CacheHit, 1, 0},
+		{"revalidated", 30 * time.Second, false, bnm.CacheRevalidated, 2, 1},
+		{"hit after revalidation", 30 * time.Second, false, bnm.CacheHit, 2, 1},
+		{"stale hit while upstream fails", time.Minute, true, bnm.CacheHit, 3, 1},
+		{"past day never revalidated", 24 * time.Hour, false, bnm.CacheHit, 3, 1},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		mu.Lock()
+		fail = step.fail
+		mu.Unlock()
+
+		resp, meta, err := client.FetchWithMeta(t.Context(), dummyQuery())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if eur, ok := resp.FindByCode("EUR"); !ok || eur.Value != 19.5 {
+			t.Fatalf("%s: unexpected response: %+v", step.name, resp)
+		}
+		if meta.Cache != step.status || meta.ETag != `"v1"` {
+			t.Errorf("%s: expected cache status %q with ETag, got %q and %q", step.name, step.status, meta.Cache, meta.ETag)
+		}
+
+		mu.Lock()
+		if requests != step.requests || notModified != step.revalidated {
+			t.Errorf("%s: want %d requests with %d revalidations, got %d with %d", step.name, step.requests, step.revalidated, requests, notModified)
+		}
+		mu.Unlock()
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected the failed revalidation to be reported, got %v", warnings)
+	}
+
+	// Without revalidation, cached responses are always served.
+	clock.Set(time.Date(2025, 1, 1, 23, 0, 0, 0, bnm.Location()))
+	client = bnm.NewClient(
+		bnm.WithBaseURL(ts.URL),
+		bnm.WithHTTPClient(ts.Client()),
+		bnm.WithCache(cache),
+		bnm.WithClock(clock),
+		bnm.WithRevalidateAfter(-1),
+	)
+	if _, meta, err := client.FetchWithMeta(t.Context(), dummyQuery()); err != nil || meta.Cache != bnm.CacheHit {
+		t.Errorf("expected a cache hit without revalidation, got %q, %v", meta.Cache, err)
+	}
+	mu.Lock()
+	if requests != 3 {
+		t.Errorf("expected no request without revalidation, got %d", requests)
+	}
+	mu.Unlock()
+}
+
+func TestClient_FetchDoesNotCacheInvalidContent(t *testing.T) {
+	body := `<ValCurs Date="01.01.2025"></ValCurs>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	sets := 0
+	cache := &mockCache{
+		getFunc: func(_ context.Context, _ string) (bnm.Response, error) {
+			return bnm.Response{}, bnm.ErrNotFound
+		},
+		setFunc: func(_ context.Context, _ string, _ bnm.Response) error {
+			sets++
+			return nil
+		},
+	}
+	client := bnm.NewClient(bnm.WithBaseURL(ts.URL), bnm.WithCache(cache))
+
+	if _, err := client.Fetch(t.Context(), dummyQuery()); !errors.Is(err, bnm.ErrNoRatesPublished) {
+		t.Errorf("expected ErrNoRatesPublished, got %v", err)
+	}
+
+	body = "<!DOCTYPE html><html><body>Maintenance</body></html>"
+	if _, err := client.Fetch(t.Context(), dummyQuery()); !errors.Is(err, bnm.ErrUnexpectedContent) {
+		t.Errorf("expected ErrUnexpectedContent, got %v", err)
+	}
+
+	if sets != 0 {
+		t.Errorf("expected nothing to be cached, got %d sets", sets)
+	}
+}
+
+func TestClient_FetchWithMetaDatePolicy(t *testing.T) {
+	// Saturday 4 January 2025 is answered with the rates of Friday 3 January.
+	saturday := bnm.NewQuery(time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+	friday := bnm.NewQuery(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+
+	tests := []struct {
+		name      string
+		policy    bnm.DatePolicy
+		wantErr   error
+		wantCache string
+	}{
+		{"accept", bnm.DateAccept, nil, saturday.ID()},
+		{"reject", bnm.DateReject, bnm.ErrDateMismatch, ""},
+		{"cache effective", bnm.DateCacheEffective, nil, friday.ID()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cached []string
+			cache := &mockCache{
+				getFunc: func(_ context.Context, _ string) (bnm.Response, error) {
+					return bnm.Response{}, bnm.ErrNotFound
+				},
+				setFunc: func(_ context.Context, key string, _ bnm.Response) error {
+					cached = append(cached, key)
+					return nil
+				},
+			}
+			client := bnm.NewClient(
+				bnm.WithCache(cache),
+				bnm.WithDatePolicy(tt.policy),
+				bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+					return nil, nil
+				}),
+				bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+					res := dummyResponse()
+					res.Date = "03.01.2025"
+					return res, nil
+				}),
+			)
+
+			_, meta, err := client.FetchWithMeta(t.Context(), saturday)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !meta.DateMismatch() {
+				t.Errorf("expected date mismatch, got %+v", meta)
+			}
+			if got := bnm.NewQuery(meta.EffectiveDate, bnm.LANG_EN).ID(); got != friday.ID() {
+				t.Errorf("expected effective date %v, got %v", friday.Date, meta.EffectiveDate)
+			}
+
+			want := []string{}
+			if tt.wantCache != "" {
+				want = []string{tt.wantCache}
+			}
+			if strings.Join(cached, ",") != strings.Join(want, ",") {
+				t.Errorf("expected cache keys %v, got %v", want, cached)
+			}
+		})
+	}
+}
+
+func TestClient_FetchWithMetaProvenance(t *testing.T) {
+	const body = `<ValCurs Date="01.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	cache, _ := bnm.NewMemoryCache(10)
+	client := bnm.NewClient(
+		bnm.WithBaseURL(ts.URL),
+		bnm.WithHTTPClient(ts.Client()),
+		bnm.WithCache(cache),
+		bnm.WithMiddleware(bnm.RetryMiddleware(2, time.Millisecond)),
+	)
+
+	before := time.Now()
+	_, meta, err := client.FetchWithMeta(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	want := bnm.Meta{
+		RequestedDate: dummyQuery().Day(),
+		EffectiveDate: meta.EffectiveDate,
+		SourceURL:     client.RequestURL(dummyQuery()),
+		FetchedAt:     meta.FetchedAt,
+		Cache:         bnm.CacheMiss,
+		HTTPStatus:    http.StatusOK,
+		SHA256:        hex.EncodeToString(sum[:]),
+		Attempts:      2,
+	}
+	if meta != want {
+		t.Errorf("expected %+v, got %+v", want, meta)
+	}
+	if meta.FetchedAt.Before(before) {
+		t.Errorf("unexpected fetch time %v", meta.FetchedAt)
+	}
+
+	_, hit, err := client.FetchWithMeta(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want.Cache = bnm.CacheHit
+	if hit != want {
+		t.Errorf("expected cache hit to report original provenance %+v, got %+v", want, hit)
+	}
+}
+
+func TestClient_FetchRaw(t *testing.T) {
+	cache := &mockCache{
+		getFunc: func(_ context.Context, _ string) (bnm.Response, error) {
+			t.Error("unexpected cache get")
+			return bnm.Response{}, bnm.ErrNotFound
+		},
+	}
+
+	var archived []byte
+	client := bnm.NewClient(
+		bnm.WithCache(cache),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			return []byte("<ValCurs/>"), nil
+		}),
+		bnm.WithArchiver(bnm.ArchiverFunc(func(_ context.Context, _ bnm.Query, raw []byte, _ bnm.Meta) error {
+			archived = raw
+			return nil
+		})),
+	)
+
+	raw, meta, err := client.FetchRaw(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(raw) != "<ValCurs/>" || string(archived) != "<ValCurs/>" {
+		t.Errorf("unexpected raw body %q, archived %q", raw, archived)
+	}
+	if meta.SHA256 == "" || meta.Cache != bnm.CacheMiss {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+}
+
+func TestFetch_PartialResponse(t *testing.T) {
+	var warned error
+	client := bnm.NewClient(
+		bnm.WithGetRequest(func(context.Context, string) ([]byte, error) {
+			return []byte(`<ValCurs Date="01.01.2025">
+				<Valute ID="47"><CharCode>EUR</CharCode><Value>19,5</Value></Valute>
+				<Valute ID="44"><CharCode>USD</CharCode><Value>n/a</Value></Valute>
+			</ValCurs>`), nil
+		}),
+		bnm.WithWarnError(func(err error) { warned = err }),
+	)
+
+	res, err := client.Fetch(t.Context(), dummyQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Currencies) != 1 || res.Currencies[0].Value != 19.5 {
+		t.Errorf("expected EUR only, got %+v", res.Currencies)
+	}
+
+	var partial *bnm.PartialResponseError
+	if !errors.As(warned, &partial) || partial.Errors[0].Code != "USD" {
+		t.Errorf("expected the skipped USD to be reported, got %v", warned)
+	}
+}
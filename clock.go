@@ -0,0 +1,73 @@
+package bnm
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the current time and schedules waits. It can be replaced with
+// WithClock, WithCacheClock or CircuitBreakerConfig.Clock to control
+// time-dependent behavior in tests; see bnmtest.FakeClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer creates a Timer that sends the current time on its channel
+	// after at least duration d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event scheduled by a Clock.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing. It returns false if the timer
+	// has already fired or been stopped.
+	Stop() bool
+}
+
+// systemClock is the Clock backed by the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTimer(d time.Duration) Timer         { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+func (t systemTimer) Stop() bool          { return t.t.Stop() }
+
+type clockKey struct{}
+
+// withClock attaches the Client clock to the request context, so that the
+// middlewares of this package schedule their waits with it.
+func withClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// clockFromContext returns the clock attached to the context, or the system clock.
+func clockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+
+	return systemClock{}
+}
+
+// Today returns midnight of the current day in Europe/Chisinau, which is the
+// day BNM rates are published for, whatever the local time zone of the system.
+func Today() time.Time {
+	return DayOf(time.Now())
+}
+
+// DayOf returns midnight of the day the instant t falls on in Europe/Chisinau.
+func DayOf(t time.Time) time.Time {
+	y, m, d := t.In(Location()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location())
+}
@@ -0,0 +1,83 @@
+package bnm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+func TestDayOf(t *testing.T) {
+	got := bnm.DayOf(time.Date(2025, 3, 30, 23, 0, 0, 0, time.UTC))
+
+	want := time.Date(2025, 3, 31, 0, 0, 0, 0, bnm.Location())
+	if !got.Equal(want) || got.Location() != bnm.Location() {
+		t.Errorf("DayOf() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_Today(t *testing.T) {
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 5, 22, 30, 0, 0, time.UTC))
+	client := bnm.NewClient(bnm.WithClock(clock))
+
+	if want := time.Date(2025, 1, 6, 0, 0, 0, 0, bnm.Location()); !client.Today().Equal(want) {
+		t.Errorf("Today() = %v, want %v", client.Today(), want)
+	}
+}
+
+func TestClient_WithClock_FetchedAt(t *testing.T) {
+	now := time.Date(2025, 1, 6, 10, 0, 0, 0, bnm.Location())
+	client := bnm.NewClient(
+		bnm.WithClock(bnmtest.NewFakeClock(now)),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			return []byte(`<ValCurs Date="06.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`), nil
+		}),
+	)
+
+	_, meta, err := client.FetchWithMeta(t.Context(), bnm.NewQuery(now, bnm.LANG_EN))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !meta.FetchedAt.Equal(now) {
+		t.Errorf("FetchedAt = %v, want %v", meta.FetchedAt, now)
+	}
+	if meta.DateMismatch() {
+		t.Errorf("unexpected date mismatch: %+v", meta)
+	}
+}
+
+func TestClient_WithClock_RetryBackoff(t *testing.T) {
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 6, 10, 0, 0, 0, bnm.Location()))
+	calls := 0
+	client := bnm.NewClient(
+		bnm.WithClock(clock),
+		bnm.WithMiddleware(bnm.RetryMiddleware(2, time.Hour)),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("connection reset")
+			}
+			return []byte(`<ValCurs Date="06.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`), nil
+		}),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Fetch(t.Context(), bnm.NewQueryForDay(2025, time.January, 6, bnm.LANG_EN))
+		done <- err
+	}()
+
+	// The retry waits an hour of fake time, not of real time.
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
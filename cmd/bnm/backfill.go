@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/backfill"
+)
+
+// runBackfill fetches a range of dates into a directory, resuming from its checkpoint.
+func runBackfill(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := fs.String("from", "", "first date to fetch, YYYY-MM-DD (required)")
+	to := fs.String("to", bnm.Today().Format("2006-01-02"), "last date to fetch, YYYY-MM-DD")
+	langs := fs.String("lang", bnm.LANG_EN, "comma-separated languages to fetch")
+	out := fs.String("out", "rates", "output directory")
+	checkpoint := fs.String("checkpoint", "", "checkpoint file (default <out>/checkpoint.json)")
+	baseURL := fs.String("base-url", bnm.DefaultBaseURL, "BNM API base URL")
+	rps := fs.Float64("rps", 1, "maximum requests per second")
+	burst := fs.Int("burst", 1, "maximum burst of requests")
+	retryFailed := fs.Bool("retry-failed", false, "retry the days that failed in a previous run")
+	nonPublication := fs.String("non-publication", "fetch", "handling of weekends and holidays: fetch, skip or carry")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		fs.Usage()
+		return errors.New("backfill: -from is required")
+	}
+
+	cfg := backfill.Config{
+		Langs:       strings.Split(*langs, ","),
+		Checkpoint:  *checkpoint,
+		RetryFailed: *retryFailed,
+	}
+	if cfg.Checkpoint == "" {
+		cfg.Checkpoint = filepath.Join(*out, "checkpoint.json")
+	}
+
+	switch *nonPublication {
+	case "fetch":
+		cfg.NonPublication = backfill.FetchAll
+	case "skip":
+		cfg.NonPublication = backfill.SkipNonPublication
+	case "carry":
+		cfg.NonPublication = backfill.CarryForward
+	default:
+		return fmt.Errorf("backfill: -non-publication: unknown value %q", *nonPublication)
+	}
+
+	var err error
+	if cfg.From, err = time.ParseInLocation("2006-01-02", *from, bnm.Location()); err != nil {
+		return fmt.Errorf("backfill: -from: %w", err)
+	}
+	if cfg.To, err = time.ParseInLocation("2006-01-02", *to, bnm.Location()); err != nil {
+		return fmt.Errorf("backfill: -to: %w", err)
+	}
+
+	sink, err := backfill.NewDirSink(*out)
+	if err != nil {
+		return fmt.Errorf("backfill: %w", err)
+	}
+
+	client := bnm.NewClient(
+		bnm.WithBaseURL(*baseURL),
+		bnm.WithRateLimit(*rps, *burst),
+		bnm.WithMiddleware(bnm.RetryMiddleware(3, time.Second)),
+		bnm.WithWarnError(func(err error) { fmt.Fprintf(os.Stderr, "bnm: warning: %v\n", err) }),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	summary, runErr := backfill.Run(ctx, client, sink, cfg)
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(summary); err != nil {
+		return fmt.Errorf("backfill: %w", err)
+	}
+
+	return runErr
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBackfill(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<ValCurs Date="%s"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`, r.URL.Query().Get("date"))
+	}))
+	defer ts.Close()
+
+	out := filepath.Join(t.TempDir(), "rates")
+	var stdout strings.Builder
+	err := run([]string{"backfill", "-from", "2025-01-02", "-to", "2025-01-03", "-lang", "en,ru", "-out", out, "-base-url", ts.URL, "-rps", "1000"}, &stdout)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), `"written": 4`) {
+		t.Errorf("unexpected summary %s", stdout.String())
+	}
+	for _, name := range []string{"en/2025-01-02.json", "ru/2025-01-03.json", "checkpoint.json"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+}
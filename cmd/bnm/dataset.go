@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/offline"
+)
+
+// runDataset regenerates an offline dataset from the documents of an archive directory.
+func runDataset(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("dataset", flag.ContinueOnError)
+	archiveDir := fs.String("archive", "", "archive directory written by bnm.DirArchiver (required)")
+	output := fs.String("o", "rates.jsonl.gz", "output dataset file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *archiveDir == "" {
+		fs.Usage()
+		return errors.New("dataset: -archive is required")
+	}
+
+	// NewDirArchiver creates missing directories: a mistyped path must not
+	// yield an empty dataset.
+	if fi, err := os.Stat(*archiveDir); err != nil {
+		return fmt.Errorf("dataset: %w", err)
+	} else if !fi.IsDir() {
+		return fmt.Errorf("dataset: %s is not a directory", *archiveDir)
+	}
+
+	archiver, err := bnm.NewDirArchiver(*archiveDir, false)
+	if err != nil {
+		return fmt.Errorf("dataset: %w", err)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("dataset: %w", err)
+	}
+	defer f.Close()
+
+	w := offline.NewWriter(f)
+	count := 0
+	err = archiver.Walk(func(query bnm.Query, raw []byte, _ bnm.Meta) error {
+		count++
+		return w.Add(query, raw)
+	})
+	if err != nil {
+		return fmt.Errorf("dataset: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("dataset: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("dataset: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "wrote %d documents to %s\n", count, *output)
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/offline"
+)
+
+func TestRunDataset(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := bnm.NewDirArchiver(filepath.Join(dir, "archive"), true)
+	if err != nil {
+		t.Fatalf("NewDirArchiver() error = %v", err)
+	}
+
+	query := bnm.NewQuery(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), bnm.LANG_RO)
+	raw := []byte(`<ValCurs Date="03.01.2025"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.2</Value></Valute></ValCurs>`)
+	if err := archiver.Archive(t.Context(), query, raw, bnm.Meta{}); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+
+	output := filepath.Join(dir, "rates.jsonl.gz")
+	if err := run([]string{"dataset", "-archive", filepath.Join(dir, "archive"), "-o", output}, io.Discard); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	ds, err := offline.LoadFile(output)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if body, err := ds.Body(query); err != nil || string(body) != string(raw) {
+		t.Errorf("Body() = %q, %v, want %q", body, err, raw)
+	}
+}
+
+func TestRunDataset_MissingArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive")
+	output := filepath.Join(dir, "rates.jsonl.gz")
+
+	if err := run([]string{"dataset", "-archive", archive, "-o", output}, io.Discard); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, name := range []string{archive, output} {
+		if _, err := os.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s was created", name)
+		}
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	if err := run([]string{"nope"}, io.Discard); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,61 @@
+// Command bnm provides tools around the BNM exchange rates client.
+//
+// Usage:
+//
+//	bnm <command> [flags]
+//
+// The commands are:
+//
+//	backfill  fetch a range of dates into a directory, resumable
+//	dataset   build an offline dataset from an archive directory
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// command is a subcommand of the bnm tool.
+type command struct {
+	name  string
+	short string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{name: "backfill", short: "fetch a range of dates into a directory, resumable", run: runBackfill},
+	{name: "dataset", short: "build an offline dataset from an archive directory", run: runDataset},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "bnm: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return fmt.Errorf("missing command")
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout)
+		}
+	}
+
+	usage(os.Stderr)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: bnm <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.short)
+	}
+}
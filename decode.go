@@ -0,0 +1,256 @@
+package bnm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxDepth is the maximum element nesting depth accepted by the default
+// unmarshaler. BNM documents are three levels deep.
+const DefaultMaxDepth = 16
+
+// DecodeOptions configures how NewUnmarshaler parses exchange rates documents.
+// The zero value is the configuration of the default unmarshaler.
+type DecodeOptions struct {
+	// StrictNumbers only accepts numbers in Go syntax, with optional surrounding
+	// whitespace. By default, comma decimal separators and digit grouping with
+	// spaces are accepted as well.
+	StrictNumbers bool
+
+	// FailOnInvalidCurrency fails the whole document when a currency cannot be
+	// parsed. By default, invalid currencies are skipped and reported with a
+	// *PartialResponseError returned alongside the other currencies.
+	FailOnInvalidCurrency bool
+
+	// MaxSize is the maximum document size in bytes. Larger documents fail with
+	// ErrBodyTooLarge. Defaults to DefaultMaxBodySize; a negative value disables the limit.
+	MaxSize int64
+
+	// MaxDepth is the maximum element nesting depth. Deeper documents fail with
+	// ErrUnexpectedContent. Defaults to DefaultMaxDepth.
+	MaxDepth int
+}
+
+// CurrencyError describes a currency of a document that could not be parsed.
+type CurrencyError struct {
+	// Index is the position of the currency in the document, starting at 0.
+	Index int
+
+	// Code is the currency code, if known.
+	Code string
+
+	Err error
+}
+
+func (e *CurrencyError) Error() string {
+	return fmt.Sprintf("currency %d (%s): %v", e.Index, e.Code, e.Err)
+}
+
+func (e *CurrencyError) Unwrap() error {
+	return e.Err
+}
+
+// PartialResponseError is returned by unmarshalers together with a usable
+// Response when some currencies of the document were skipped.
+// Client.Fetch returns such responses and reports the error to the WarnFunc.
+type PartialResponseError struct {
+	Errors []*CurrencyError
+}
+
+func (e *PartialResponseError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d invalid currencies skipped: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *PartialResponseError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// NewUnmarshaler returns an UnmarshalerFunc parsing BNM exchange rates documents
+// with the given options, to be used with WithUnmarshaler.
+func NewUnmarshaler(opts DecodeOptions) UnmarshalerFunc {
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultMaxBodySize
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+
+	return func(data []byte) (Response, error) {
+		res, err := decodeResponse(data, opts)
+		if err != nil {
+			return res, fmt.Errorf("unmarshal response: %w", err)
+		}
+		return res, nil
+	}
+}
+
+// rawCurrency is a currency as written in the document, before number parsing.
+type rawCurrency struct {
+	ID      string `xml:"ID,attr"`
+	NumCode []byte
+	Code    string `xml:"CharCode"`
+	Nominal []byte
+	Name    string
+	Value   []byte
+}
+
+type rawResponse struct {
+	Date       string        `xml:"Date,attr"`
+	Name       string        `xml:"name,attr"`
+	Currencies []rawCurrency `xml:"Valute"`
+}
+
+func decodeResponse(data []byte, opts DecodeOptions) (Response, error) {
+	if opts.MaxSize > 0 && int64(len(data)) > opts.MaxSize {
+		return Response{}, fmt.Errorf("%d bytes: %w", len(data), ErrBodyTooLarge)
+	}
+
+	if err := checkPayload(data); err != nil {
+		return Response{}, err
+	}
+
+	raw, err := newStreamDecoder(data, opts.MaxDepth).decode()
+	if err != nil {
+		return Response{}, err
+	}
+
+	return buildResponse(raw, opts)
+}
+
+// buildResponse parses the numbers of a decoded document and validates it.
+func buildResponse(raw rawResponse, opts DecodeOptions) (Response, error) {
+	res := Response{Date: raw.Date, Name: raw.Name}
+	if _, err := res.Time(); err != nil {
+		return Response{}, fmt.Errorf("%w: %v", ErrUnexpectedContent, err)
+	}
+
+	res.Currencies = make([]Currency, 0, len(raw.Currencies))
+	var partial PartialResponseError
+	for i, rc := range raw.Currencies {
+		cur, err := parseCurrency(rc, opts.StrictNumbers)
+		if err != nil {
+			cerr := &CurrencyError{Index: i, Code: strings.TrimSpace(rc.Code), Err: err}
+			if opts.FailOnInvalidCurrency {
+				return Response{}, cerr
+			}
+			partial.Errors = append(partial.Errors, cerr)
+			continue
+		}
+		res.Currencies = append(res.Currencies, cur)
+	}
+
+	if len(res.Currencies) == 0 {
+		if len(partial.Errors) > 0 {
+			return Response{}, fmt.Errorf("date %q: %w: %w", res.Date, ErrNoRatesPublished, &partial)
+		}
+		return Response{}, fmt.Errorf("date %q: %w", res.Date, ErrNoRatesPublished)
+	}
+
+	if len(partial.Errors) > 0 {
+		return res, &partial
+	}
+
+	return res, nil
+}
+
+func parseCurrency(rc rawCurrency, strict bool) (Currency, error) {
+	cur := Currency{ID: rc.ID, Code: rc.Code, Name: rc.Name}
+	if !strict {
+		cur.Code = strings.TrimSpace(rc.Code)
+	}
+
+	var err error
+	if cur.NumCode, err = parseInt(rc.NumCode); err != nil {
+		return Currency{}, fmt.Errorf("NumCode: %w", err)
+	}
+	if cur.Nominal, err = parseInt(rc.Nominal); err != nil {
+		return Currency{}, fmt.Errorf("Nominal: %w", err)
+	}
+
+	value, err := parseNumber(rc.Value, strict)
+	if err != nil {
+		return Currency{}, fmt.Errorf("Value: %w", err)
+	}
+	cur.Value = float32(value)
+
+	return cur, nil
+}
+
+// parseInt parses an optional integer; an empty number is zero.
+func parseInt(b []byte) (int, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	return strconv.Atoi(string(b))
+}
+
+// parseNumber parses a mandatory, finite and non-negative decimal number.
+// Unless strict, the last comma or dot is the decimal separator while spaces,
+// apostrophes and the other commas and dots group digits.
+func parseNumber(b []byte, strict bool) (float64, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return 0, errors.New("empty number")
+	}
+
+	if !strict && !isPlainNumber(b) {
+		b = bytes.Map(func(r rune) rune {
+			switch r {
+			case ' ', '\u00a0', '\u202f', '\'':
+				return -1
+			}
+			return r
+		}, b)
+
+		if i := bytes.LastIndexAny(b, ".,"); i >= 0 {
+			b = append(append(bytes.Map(func(r rune) rune {
+				if r == '.' || r == ',' {
+					return -1
+				}
+				return r
+			}, b[:i]), '.'), b[i+1:]...)
+		}
+	}
+
+	v, err := strconv.ParseFloat(string(b), 32)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid rate %q", b)
+	}
+
+	return v, nil
+}
+
+// isPlainNumber reports whether b holds only digits and at most one dot, as
+// BNM rates do, so that no separator needs to be normalized.
+func isPlainNumber(b []byte) bool {
+	dots := 0
+	for _, c := range b {
+		switch {
+		case c == '.':
+			dots++
+		case c < '0' || c > '9':
+			return false
+		}
+	}
+
+	return dots <= 1
+}
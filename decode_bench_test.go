@@ -0,0 +1,28 @@
+package bnm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchmarkDocument returns the sample Russian document of the bnmtest package.
+func benchmarkDocument(b *testing.B) []byte {
+	data, err := os.ReadFile(filepath.Join("bnmtest", "fixtures", "ru", "2025-01-06.xml"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkUnmarshalResponse(b *testing.B) {
+	data := benchmarkDocument(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := unmarshalResponse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
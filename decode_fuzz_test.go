@@ -0,0 +1,117 @@
+package bnm
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// addFixtureCorpus seeds the fuzzer with the documents of fixturePaths.
+func addFixtureCorpus(f *testing.F) {
+	paths, err := fixturePaths()
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// fixturePaths lists the synthetic fixtures of the bnmtest package and their
+// legacy encodings.
+func fixturePaths() ([]string, error) {
+	var paths []string
+	for _, pattern := range []string{
+		filepath.Join("bnmtest", "fixtures", "*", "*.xml"),
+		filepath.Join("testdata", "charset", "*.xml"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+func FuzzUnmarshalResponse(f *testing.F) {
+	addFixtureCorpus(f)
+	f.Add([]byte(`<ValCurs Date="05.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><Value>21,2997</Value></Valute></ValCurs>`))
+	f.Add([]byte(`<ValCurs Date="05.08.2017"><Valute ID="44"><CharCode>USD</CharCode><Value/></Valute><Valute ID="47"><Value> 1 234.5 </Value></Valute></ValCurs>`))
+	f.Add([]byte(`<?xml version="1.0"?><ValCurs Date="05.08.2017" name="x"></ValCurs>`))
+	f.Add([]byte(`<!DOCTYPE html><html></html>`))
+	f.Add([]byte(`<ValCurs Date="05.08.2017"><a><b><c><d></d></c></b></a></ValCurs>`))
+
+	strict := NewUnmarshaler(DecodeOptions{StrictNumbers: true, FailOnInvalidCurrency: true})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		res, err := unmarshalResponse(data)
+
+		// Partial responses are usable as long as some currencies are left.
+		var partial *PartialResponseError
+		usable := err == nil || (errors.As(err, &partial) && len(res.Currencies) > 0)
+		if !usable {
+			if len(res.Currencies) != 0 {
+				t.Fatalf("failed unmarshal returned currencies: %v", err)
+			}
+		} else {
+			if _, err := res.Time(); err != nil {
+				t.Fatalf("usable unmarshal returned an invalid date: %v", err)
+			}
+			for _, cur := range res.Currencies {
+				if v := float64(cur.Value); v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+					t.Fatalf("invalid rate %v", cur.Value)
+				}
+			}
+		}
+
+		// Whatever the strict unmarshaler accepts, the lenient one accepts identically.
+		sres, serr := strict(data)
+		if serr != nil {
+			return
+		}
+		if err != nil {
+			t.Fatalf("strict unmarshal succeeded but lenient returned %v", err)
+		}
+		if len(sres.Currencies) != len(res.Currencies) {
+			t.Fatalf("strict unmarshal returned %d currencies, lenient %d", len(sres.Currencies), len(res.Currencies))
+		}
+		for i := range sres.Currencies {
+			if sres.Currencies[i].Value != res.Currencies[i].Value {
+				t.Fatalf("currency %d: strict value %v, lenient %v", i, sres.Currencies[i].Value, res.Currencies[i].Value)
+			}
+		}
+	})
+}
+
+func FuzzParseNumber(f *testing.F) {
+	for _, s := range []string{"21.2997", "21,2997", " 4.6680\n", "1 234,5", "1.234,5", "", "-1", "NaN", "1e39", "0x1p-2", "."} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := parseNumber([]byte(s), false)
+		if err == nil && (v < 0 || math.IsNaN(v) || math.IsInf(v, 0)) {
+			t.Fatalf("parseNumber(%q) = %v", s, v)
+		}
+
+		// Numbers formatted with a dot or a comma parse identically.
+		if sv, err := parseNumber([]byte(s), true); err == nil && !strings.ContainsAny(s, ",") {
+			f := strconv.FormatFloat(sv, 'f', -1, 32)
+			for _, formatted := range []string{f, strings.Replace(f, ".", ",", 1)} {
+				if lv, err := parseNumber([]byte(formatted), false); err != nil || float32(lv) != float32(sv) {
+					t.Fatalf("parseNumber(%q) = %v, %v, want %v", formatted, lv, err, sv)
+				}
+			}
+		}
+	})
+}
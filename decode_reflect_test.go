@@ -0,0 +1,208 @@
+package bnm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// reflectUnmarshaler is the reflection-based unmarshaler the streaming decoder
+// replaced, kept as the reference implementation the latter must agree with.
+func reflectUnmarshaler(opts DecodeOptions) UnmarshalerFunc {
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultMaxBodySize
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+
+	return func(data []byte) (Response, error) {
+		res, err := reflectDecodeResponse(data, opts)
+		if err != nil {
+			return res, fmt.Errorf("unmarshal response: %w", err)
+		}
+		return res, nil
+	}
+}
+
+func reflectDecodeResponse(data []byte, opts DecodeOptions) (Response, error) {
+	if opts.MaxSize > 0 && int64(len(data)) > opts.MaxSize {
+		return Response{}, fmt.Errorf("%d bytes: %w", len(data), ErrBodyTooLarge)
+	}
+
+	if err := checkContent(data); err != nil {
+		return Response{}, err
+	}
+
+	var raw rawResponse
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charsetReader
+	tokens := &depthLimiter{dec: dec, max: opts.MaxDepth}
+	if err := xml.NewTokenDecoder(tokens).Decode(&raw); err != nil {
+		return Response{}, err
+	}
+
+	return buildResponse(raw, opts)
+}
+
+// checkContent verifies that data looks like a BNM exchange rates document:
+// it must not be empty nor HTML, and its root element must be ValCurs.
+func checkContent(data []byte) error {
+	if err := checkPayload(data); err != nil {
+		return err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charsetReader
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: no root element", ErrUnexpectedContent)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnexpectedContent, err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != rootElement {
+				return fmt.Errorf("%w: root element %q", ErrUnexpectedContent, start.Name.Local)
+			}
+			return nil
+		}
+	}
+}
+
+// depthLimiter is an xml.TokenReader failing on elements nested deeper than max.
+type depthLimiter struct {
+	dec   *xml.Decoder
+	depth int
+	max   int
+}
+
+func (l *depthLimiter) Token() (xml.Token, error) {
+	tok, err := l.dec.Token()
+	if err != nil {
+		return tok, err
+	}
+
+	switch tok.(type) {
+	case xml.StartElement:
+		l.depth++
+		if l.depth > l.max {
+			return nil, fmt.Errorf("%w: elements nested deeper than %d", ErrUnexpectedContent, l.max)
+		}
+	case xml.EndElement:
+		l.depth--
+	}
+
+	return tok, nil
+}
+
+// decoderDocuments are documents exercising the corners of the XML syntax
+// accepted by both decoders.
+var decoderDocuments = []string{
+	`<ValCurs Date="05.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><Value>21,2997</Value></Valute></ValCurs>`,
+	`<?xml version="1.0"?><!-- rates --><ValCurs name="x" Date="05.08.2017" Date="06.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><CharCode>USD</CharCode><Value>1<![CDATA[7.5]]></Value></Valute></ValCurs>`,
+	`<b:ValCurs xmlns:b="urn:bnm" b:Date="05.08.2017"><b:Valute ID="47"><b:CharCode>EUR</b:CharCode><b:Value>21.2997<x>9</x></b:Value><Other><Value>1</Value></Other></b:Valute></b:ValCurs>`,
+	`<ValCurs Date="05.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><Value>1</Value></Valute></ValCurs><trailing`,
+	`<ValCurs Date="05.08.2017"><Valute ID="47"><Name>&lt;Euro&gt; &amp; co</Name><Value>1</Value></Valute><Valute><Value>2</Value>`,
+	`<ValCurs Date="05.08.2017"><Valute><Value>1</Wrong></Valute></ValCurs>`,
+	`<ValCurs Date="05.08.2017"><a><b><c><d><e></e></d></c></b></a></ValCurs>`,
+	`<ValCurs Date="05.08.2017"><Valute><NumCode> 978 </NumCode><Nominal>x</Nominal><Value>1 234,5</Value></Valute><Valute><Value>1'234.5</Value></Valute></ValCurs>`,
+	`<b:ValCurs xmlns:b="urn:bnm" Date="05.08.2017"><Valute><Value>1</Value></Valute></c:ValCurs>`,
+	`<ValCurs Date="05.08.2017"><Valute><Value>1</Value>
+</Valute>`,
+	`</a><ValCurs Date="05.08.2017"/>`,
+	`<?xml version="1.0"?>`,
+	`<Error>boom</Error>`,
+	`<<<`,
+}
+
+// assertSameResult fails the test if the streaming and reflection-based
+// decoders disagree on data.
+func assertSameResult(t *testing.T, data []byte, opts DecodeOptions) {
+	t.Helper()
+
+	want, wantErr := reflectUnmarshaler(opts)(data)
+	got, gotErr := NewUnmarshaler(opts)(data)
+	if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+		t.Fatalf("streaming decoder returned error %v, reflection %v", gotErr, wantErr)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("streaming decoder returned %+v, reflection %+v", got, want)
+	}
+}
+
+func TestStreamDecoder_MatchesReflection(t *testing.T) {
+	paths, err := fixturePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fuzzPaths, err := filepath.Glob(filepath.Join("testdata", "fuzz", "*", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := map[string][]byte{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs[path] = data
+	}
+	for _, path := range fuzzPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Corpus files hold a []byte("...") line after the version header.
+		if _, value, ok := strings.Cut(string(data), "[]byte("); ok {
+			var s string
+			if _, err := fmt.Sscanf(strings.TrimSuffix(strings.TrimSpace(value), ")"), "%q", &s); err == nil {
+				docs[path] = []byte(s)
+			}
+		}
+	}
+	for i, doc := range decoderDocuments {
+		docs[fmt.Sprintf("document %d", i)] = []byte(doc)
+	}
+
+	for name, data := range docs {
+		t.Run(name, func(t *testing.T) {
+			assertSameResult(t, data, DecodeOptions{})
+			assertSameResult(t, data, DecodeOptions{StrictNumbers: true, FailOnInvalidCurrency: true, MaxDepth: 3})
+		})
+	}
+}
+
+func FuzzStreamDecoder(f *testing.F) {
+	addFixtureCorpus(f)
+	for _, doc := range decoderDocuments {
+		f.Add([]byte(doc))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		assertSameResult(t, data, DecodeOptions{MaxDepth: 4})
+	})
+}
+
+func BenchmarkUnmarshalResponse_Reflection(b *testing.B) {
+	data := benchmarkDocument(b)
+	unmarshal := reflectUnmarshaler(DecodeOptions{})
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := unmarshal(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package bnm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamDecoder reads a BNM exchange rates document token by token into a
+// rawResponse, without reflection. It accepts the same documents as
+// xml.Unmarshal into a rawResponse and checks the root element on the way.
+//
+// It reads raw tokens, which are neither copied nor namespace-translated, and
+// matches elements by their local name only; the nesting that xml.Decoder.Token
+// would verify is checked against the stack of open elements instead.
+type streamDecoder struct {
+	dec   *xml.Decoder
+	open  []xml.Name
+	max   int
+	text  []byte
+	bytes []byte
+}
+
+func newStreamDecoder(data []byte, maxDepth int) *streamDecoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charsetReader
+
+	return &streamDecoder{dec: dec, max: maxDepth}
+}
+
+// token returns the next raw token, enforcing the depth limit and reporting
+// unbalanced elements and truncated documents like xml.Decoder.Token.
+func (s *streamDecoder) token() (xml.Token, error) {
+	tok, err := s.dec.RawToken()
+	if errors.Is(err, io.EOF) && len(s.open) > 0 {
+		return nil, s.syntaxError("unexpected EOF")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case xml.StartElement:
+		if len(s.open) == s.max {
+			return nil, fmt.Errorf("%w: elements nested deeper than %d", ErrUnexpectedContent, s.max)
+		}
+		s.open = append(s.open, t.Name)
+	case xml.EndElement:
+		if len(s.open) == 0 {
+			return nil, s.syntaxError("unexpected end element </" + t.Name.Local + ">")
+		}
+
+		start := s.open[len(s.open)-1]
+		switch {
+		case start.Local != t.Name.Local:
+			return nil, s.syntaxError("element <" + start.Local + "> closed by </" + t.Name.Local + ">")
+		case start.Space != t.Name.Space:
+			space := t.Name.Space
+			if space == "" {
+				space = `""`
+			}
+			return nil, s.syntaxError("element <" + start.Local + "> in space " + start.Space +
+				" closed by </" + t.Name.Local + "> in space " + space)
+		}
+		s.open = s.open[:len(s.open)-1]
+	}
+
+	return tok, nil
+}
+
+// syntaxError returns the error xml.Decoder.Token reports for msg.
+func (s *streamDecoder) syntaxError(msg string) error {
+	line, _ := s.dec.InputPos()
+	return &xml.SyntaxError{Msg: msg, Line: line}
+}
+
+// decode reads the document. Failures before the root element is found are
+// reported with ErrUnexpectedContent, like those of checkPayload.
+func (s *streamDecoder) decode() (rawResponse, error) {
+	var raw rawResponse
+
+	root, err := s.root()
+	if err != nil {
+		return raw, err
+	}
+	for _, attr := range root.Attr {
+		switch attr.Name.Local {
+		case "Date":
+			raw.Date = attr.Value
+		case "name":
+			raw.Name = attr.Value
+		}
+	}
+
+	for {
+		tok, err := s.token()
+		if err != nil {
+			return raw, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "Valute" {
+				if err := s.skip(); err != nil {
+					return raw, err
+				}
+				continue
+			}
+
+			cur, err := s.currency(t)
+			if err != nil {
+				return raw, err
+			}
+			raw.Currencies = append(raw.Currencies, cur)
+		case xml.EndElement:
+			return raw, nil
+		}
+	}
+}
+
+// root returns the root element, which must be ValCurs.
+func (s *streamDecoder) root() (xml.StartElement, error) {
+	for {
+		tok, err := s.token()
+		if errors.Is(err, io.EOF) {
+			return xml.StartElement{}, fmt.Errorf("%w: no root element", ErrUnexpectedContent)
+		}
+		if err != nil {
+			return xml.StartElement{}, fmt.Errorf("%w: %v", ErrUnexpectedContent, err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != rootElement {
+				return xml.StartElement{}, fmt.Errorf("%w: root element %q", ErrUnexpectedContent, start.Name.Local)
+			}
+			return start, nil
+		}
+	}
+}
+
+// currency reads a Valute element whose start has just been read.
+func (s *streamDecoder) currency(start xml.StartElement) (rawCurrency, error) {
+	var cur rawCurrency
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "ID" {
+			cur.ID = attr.Value
+		}
+	}
+
+	for {
+		tok, err := s.token()
+		if err != nil {
+			return cur, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "NumCode":
+				cur.NumCode, err = s.chardataBytes()
+			case "CharCode":
+				cur.Code, err = s.chardata()
+			case "Nominal":
+				cur.Nominal, err = s.chardataBytes()
+			case "Name":
+				cur.Name, err = s.chardata()
+			case "Value":
+				cur.Value, err = s.chardataBytes()
+			default:
+				err = s.skip()
+			}
+			if err != nil {
+				return cur, err
+			}
+		case xml.EndElement:
+			return cur, nil
+		}
+	}
+}
+
+// chardata returns the character data directly inside the element whose
+// start has just been read, skipping nested elements.
+func (s *streamDecoder) chardata() (string, error) {
+	if err := s.readText(); err != nil {
+		return "", err
+	}
+
+	return string(s.text), nil
+}
+
+// chardataBytes is like chardata, but returns the text as a slice of a buffer
+// shared by the whole document, so that numbers are parsed without a string
+// being allocated for each of them.
+func (s *streamDecoder) chardataBytes() ([]byte, error) {
+	if err := s.readText(); err != nil {
+		return nil, err
+	}
+
+	// Earlier slices keep the previous array if appending reallocates.
+	n := len(s.bytes)
+	s.bytes = append(s.bytes, s.text...)
+	return s.bytes[n:len(s.bytes):len(s.bytes)], nil
+}
+
+// readText reads the character data of chardata into s.text.
+func (s *streamDecoder) readText() error {
+	s.text = s.text[:0]
+	for {
+		tok, err := s.token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			s.text = append(s.text, t...)
+		case xml.StartElement:
+			if err := s.skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// skip consumes the element whose start has just been read.
+func (s *streamDecoder) skip() error {
+	depth := len(s.open)
+	for len(s.open) >= depth {
+		if _, err := s.token(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
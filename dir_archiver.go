@@ -0,0 +1,261 @@
+package bnm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2/internal/atomicfile"
+)
+
+const (
+	archiveObjectsDir = "objects"
+	archiveQueriesDir = "queries"
+	archiveDateFormat = "2006-01-02"
+)
+
+// DirArchiver is an Archiver storing documents in a directory.
+//
+// Documents are content-addressed: each distinct body is stored once under
+// objects/<sha256>.xml (or .xml.gz when compressed), and queries/<query id>.json
+// records which document and metadata the latest fetch of each query returned.
+// It is safe for concurrent use by multiple goroutines.
+type DirArchiver struct {
+	dir      string
+	compress bool
+}
+
+// archiveRecord is the JSON document describing an archived query.
+type archiveRecord struct {
+	Date   string `json:"date"`
+	Lang   string `json:"lang"`
+	Object string `json:"object"`
+	Meta   Meta   `json:"meta"`
+}
+
+var _ Archiver = (*DirArchiver)(nil)
+
+// NewDirArchiver creates a DirArchiver storing documents in dir, creating it if needed.
+// If compress is true, new documents are gzip-compressed.
+func NewDirArchiver(dir string, compress bool) (*DirArchiver, error) {
+	for _, sub := range []string{archiveObjectsDir, archiveQueriesDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("create archive directory: %w", err)
+		}
+	}
+
+	return &DirArchiver{dir: dir, compress: compress}, nil
+}
+
+// Archive stores the raw body, unless an identical one is already stored,
+// and records it as the latest document of the query.
+func (a *DirArchiver) Archive(ctx context.Context, query Query, raw []byte, meta Meta) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(raw)
+	object := hex.EncodeToString(sum[:]) + ".xml"
+	data := raw
+	if a.compress {
+		object += ".gz"
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(raw); err != nil {
+			return fmt.Errorf("compress: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compress: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	objectPath := filepath.Join(a.dir, archiveObjectsDir, object)
+	if _, err := os.Stat(objectPath); errors.Is(err, fs.ErrNotExist) {
+		if err := atomicfile.Write(objectPath, data); err != nil {
+			return fmt.Errorf("write object: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("stat object: %w", err)
+	}
+
+	record, err := json.MarshalIndent(archiveRecord{
+		Date:   query.Day().Format(archiveDateFormat),
+		Lang:   query.Lang,
+		Object: object,
+		Meta:   meta,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+
+	if err := atomicfile.Write(a.recordPath(query.ID()), record); err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the latest archived document of the query and its metadata.
+// It returns ErrNotFound if the query has not been archived.
+func (a *DirArchiver) Load(query Query) ([]byte, Meta, error) {
+	record, err := a.readRecord(a.recordPath(query.ID()))
+	if err != nil {
+		return nil, Meta{}, err
+	}
+
+	raw, err := a.readObject(record.Object)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+
+	return raw, record.Meta, nil
+}
+
+// Walk calls fn for the latest archived document of every query, in query ID order.
+// It stops at the first error returned by fn or encountered while reading the archive.
+func (a *DirArchiver) Walk(fn func(query Query, raw []byte, meta Meta) error) error {
+	paths, err := filepath.Glob(filepath.Join(a.dir, archiveQueriesDir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list records: %w", err)
+	}
+
+	for _, path := range paths {
+		query, raw, meta, err := a.loadRecord(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+
+		if err := fn(query, raw, meta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Replay parses every archived document and stores it in the cache under the
+// ID of its query, together with its metadata if the cache implements MetaCache.
+// Documents that cannot be read or parsed are skipped and reported in the
+// returned error. It returns the number of responses stored.
+func (a *DirArchiver) Replay(ctx context.Context, cache Cache) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(a.dir, archiveQueriesDir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("list records: %w", err)
+	}
+
+	// Archived documents were accepted by a client, whatever its body size limit.
+	unmarshal := NewUnmarshaler(DecodeOptions{MaxSize: -1})
+
+	var errs []error
+	stored := 0
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return stored, err
+		}
+
+		query, raw, meta, err := a.loadRecord(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+
+		res, err := unmarshal(raw)
+		var partial *PartialResponseError
+		if errors.As(err, &partial) && len(res.Currencies) > 0 {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		meta.EffectiveDate, _ = res.Time()
+
+		if err := setCache(ctx, cache, query.ID(), res, meta); err != nil {
+			errs = append(errs, fmt.Errorf("%s: set cache: %w", filepath.Base(path), err))
+			continue
+		}
+		stored++
+	}
+
+	return stored, errors.Join(errs...)
+}
+
+func (a *DirArchiver) loadRecord(path string) (Query, []byte, Meta, error) {
+	record, err := a.readRecord(path)
+	if err != nil {
+		return Query{}, nil, Meta{}, err
+	}
+
+	date, err := time.ParseInLocation(archiveDateFormat, record.Date, Location())
+	if err != nil {
+		return Query{}, nil, Meta{}, fmt.Errorf("parse date: %w", err)
+	}
+
+	raw, err := a.readObject(record.Object)
+	if err != nil {
+		return Query{}, nil, Meta{}, err
+	}
+
+	return NewQuery(date, record.Lang), raw, record.Meta, nil
+}
+
+func (a *DirArchiver) recordPath(id string) string {
+	return filepath.Join(a.dir, archiveQueriesDir, id+".json")
+}
+
+func (a *DirArchiver) readRecord(path string) (archiveRecord, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return archiveRecord{}, ErrNotFound
+	}
+	if err != nil {
+		return archiveRecord{}, fmt.Errorf("read record: %w", err)
+	}
+
+	var record archiveRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return archiveRecord{}, fmt.Errorf("unmarshal record: %w", err)
+	}
+
+	return record, nil
+}
+
+func (a *DirArchiver) readObject(object string) ([]byte, error) {
+	if object != filepath.Base(object) {
+		return nil, fmt.Errorf("invalid object name %q", object)
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.dir, archiveObjectsDir, object))
+	if err != nil {
+		return nil, fmt.Errorf("read object: %w", err)
+	}
+
+	if !strings.HasSuffix(object, ".gz") {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress object: %w", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompress object: %w", err)
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,146 @@
+package bnm_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+const archiveTestBody = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="03.01.2025" name="Official exchange rate"><Valute ID="47"><NumCode>978</NumCode><CharCode>EUR</CharCode><Nominal>1</Nominal><Name>Euro</Name><Value>19.1234</Value></Valute></ValCurs>`
+
+func TestDirArchiver(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, archiveTestBody)
+			}))
+			defer ts.Close()
+
+			dir := t.TempDir()
+			archiver, err := bnm.NewDirArchiver(dir, compress)
+			if err != nil {
+				t.Fatalf("NewDirArchiver() error = %v", err)
+			}
+
+			client := bnm.NewClient(
+				bnm.WithBaseURL(ts.URL),
+				bnm.WithHTTPClient(ts.Client()),
+				bnm.WithArchiver(archiver),
+				bnm.WithWarnError(func(err error) { t.Errorf("unexpected warning: %v", err) }),
+			)
+
+			friday := bnm.NewQuery(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+			saturday := bnm.NewQuery(time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+			if _, err := client.Fetch(t.Context(), friday); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if _, _, err := client.FetchRaw(t.Context(), saturday); err != nil {
+				t.Fatalf("FetchRaw() error = %v", err)
+			}
+
+			objects, _ := filepath.Glob(filepath.Join(dir, "objects", "*"))
+			if len(objects) != 1 {
+				t.Errorf("expected identical bodies to be stored once, got %v", objects)
+			}
+
+			raw, meta, err := archiver.Load(saturday)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if string(raw) != archiveTestBody {
+				t.Errorf("Load() returned %q, want %q", raw, archiveTestBody)
+			}
+			if meta.SourceURL != client.RequestURL(saturday) {
+				t.Errorf("Load() meta source URL = %q, want %q", meta.SourceURL, client.RequestURL(saturday))
+			}
+
+			cache, _ := bnm.NewMemoryCache(10)
+			n, err := archiver.Replay(t.Context(), cache)
+			if err != nil || n != 2 {
+				t.Fatalf("Replay() = %d, %v, want 2, nil", n, err)
+			}
+
+			res, meta, err := cache.GetWithMeta(t.Context(), friday.ID())
+			if err != nil {
+				t.Fatalf("GetWithMeta() error = %v", err)
+			}
+			if eur, ok := res.FindByCode("EUR"); !ok || eur.Value != 19.1234 {
+				t.Errorf("unexpected replayed response %+v", res)
+			}
+			if meta.SHA256 == "" || meta.EffectiveDate.IsZero() {
+				t.Errorf("expected replayed provenance, got %+v", meta)
+			}
+		})
+	}
+}
+
+func TestDirArchiver_NonMidnightQuery(t *testing.T) {
+	archiver, err := bnm.NewDirArchiver(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("NewDirArchiver() error = %v", err)
+	}
+
+	// 22:30 UTC on the 9th is already the 10th in Chisinau.
+	query := bnm.NewQuery(time.Date(2025, 1, 9, 22, 30, 0, 0, time.UTC), bnm.LANG_EN)
+	if err := archiver.Archive(t.Context(), query, []byte(archiveTestBody), bnm.Meta{}); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+
+	err = archiver.Walk(func(q bnm.Query, _ []byte, _ bnm.Meta) error {
+		if q.ID() != query.ID() {
+			t.Errorf("Walk() rebuilt query %s, want %s", q.ID(), query.ID())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	cache, _ := bnm.NewMemoryCache(10)
+	if n, err := archiver.Replay(t.Context(), cache); err != nil || n != 1 {
+		t.Fatalf("Replay() = %d, %v, want 1, nil", n, err)
+	}
+	if _, err := cache.Get(t.Context(), query.ID()); err != nil {
+		t.Errorf("Get() of the original query error = %v", err)
+	}
+}
+
+func TestDirArchiver_LoadNotFound(t *testing.T) {
+	archiver, err := bnm.NewDirArchiver(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("NewDirArchiver() error = %v", err)
+	}
+
+	if _, _, err := archiver.Load(dummyQuery()); !errors.Is(err, bnm.ErrNotFound) {
+		t.Errorf("Load() error = %v, want %v", err, bnm.ErrNotFound)
+	}
+}
+
+func TestDirArchiver_ReplaySkipsInvalidDocuments(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := bnm.NewDirArchiver(dir, false)
+	if err != nil {
+		t.Fatalf("NewDirArchiver() error = %v", err)
+	}
+
+	if err := archiver.Archive(t.Context(), dummyQuery(), []byte("<html>maintenance</html>"), bnm.Meta{}); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "queries", "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, _ := bnm.NewMemoryCache(10)
+	n, err := archiver.Replay(t.Context(), cache)
+	if n != 0 || err == nil {
+		t.Errorf("Replay() = %d, %v, want 0 and an error", n, err)
+	}
+}
@@ -0,0 +1,88 @@
+package bnm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxLookBack is the number of days EffectiveRate walks back by default
+// to find published rates. It covers the longest run of weekends and holidays.
+const DefaultMaxLookBack = 14
+
+// effectiveDatesCapacity is the number of requested-to-effective date mappings
+// remembered by EffectiveRate.
+const effectiveDatesCapacity = 1024
+
+// EffectiveRate returns the rate of the currency with the given code in force
+// on date, together with the date the rate was published for, both in
+// Europe/Chisinau. For weekends and
+// holidays this is the rate of the previous publication day.
+//
+// The effective date is found with the calendar set with WithCalendar and
+// confirmed with the date of the response, so that days off missing from the
+// calendar are handled as well. EffectiveRate walks back at most the number
+// of days set with WithMaxLookBack and fails with ErrNoRatesPublished beyond.
+// Currencies missing from the effective rates fail with ErrCurrencyNotFound.
+//
+// The mapping from past requested dates to effective dates is remembered,
+// so that repeated lookups fetch the effective date directly. Rates are fetched
+// in English, through the cache if any.
+func (c *Client) EffectiveRate(ctx context.Context, date time.Time, code string) (Currency, time.Time, error) {
+	if c.err != nil {
+		return Currency{}, time.Time{}, c.err
+	}
+
+	date = civilDay(date)
+	key := date.Format(isoDateFormat)
+	if effective, ok := c.effectiveDates.get(key); ok {
+		res, err := c.Fetch(ctx, NewQuery(effective, LANG_EN))
+		if err != nil {
+			return Currency{}, time.Time{}, err
+		}
+		return findRate(res, effective, code)
+	}
+
+	earliest := date.AddDate(0, 0, -c.maxLookBack)
+	day := c.calendar.EffectiveDay(date)
+	for !day.Before(earliest) {
+		res, meta, err := c.FetchWithMeta(ctx, NewQuery(day, LANG_EN))
+		effective := day
+		if !meta.EffectiveDate.IsZero() {
+			effective = civilDay(meta.EffectiveDate)
+		}
+
+		switch {
+		case errors.Is(err, ErrNoRatesPublished):
+			day = c.calendar.PreviousPublicationDay(day)
+			continue
+		case errors.Is(err, ErrDateMismatch) && effective.Before(day):
+			// A day off missing from the calendar: fetch the date BNM answered with.
+			day = effective
+			continue
+		case err != nil:
+			return Currency{}, time.Time{}, err
+		case effective.Before(earliest):
+			day = effective
+			continue
+		}
+
+		// Rates for today or later may still be published.
+		if date.Before(c.Today()) {
+			c.effectiveDates.set(key, effective)
+		}
+		return findRate(res, effective, code)
+	}
+
+	return Currency{}, time.Time{}, fmt.Errorf("rate in force on %s: nothing published in the previous %d days: %w", key, c.maxLookBack, ErrNoRatesPublished)
+}
+
+func findRate(res Response, effective time.Time, code string) (Currency, time.Time, error) {
+	cur, ok := res.FindByCode(code)
+	if !ok {
+		return Currency{}, time.Time{}, fmt.Errorf("%s on %s: %w", code, effective.Format(isoDateFormat), ErrCurrencyNotFound)
+	}
+
+	return cur, effective, nil
+}
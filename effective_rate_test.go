@@ -0,0 +1,135 @@
+package bnm_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+// closedUpstream answers like BNM: days off get the rates of the previous open day.
+type closedUpstream struct {
+	mu     sync.Mutex
+	closed map[string]bool
+	empty  bool
+	calls  []string
+}
+
+func (u *closedUpstream) get(_ context.Context, rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	date := parsed.Query().Get("date")
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.calls = append(u.calls, date)
+
+	if u.empty {
+		return []byte(`<ValCurs Date="` + date + `"></ValCurs>`), nil
+	}
+
+	d, err := time.Parse("02.01.2006", date)
+	if err != nil {
+		return nil, err
+	}
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || u.closed[d.Format("02.01.2006")] {
+		d = d.AddDate(0, 0, -1)
+	}
+
+	return []byte(`<ValCurs Date="` + d.Format("02.01.2006") + `"><Valute ID="47"><CharCode>EUR</CharCode><Value>19.5</Value></Valute></ValCurs>`), nil
+}
+
+func TestEffectiveRate_Weekend(t *testing.T) {
+	upstream := &closedUpstream{}
+	client := bnm.NewClient(bnm.WithGetRequest(upstream.get))
+
+	cur, effective, err := client.EffectiveRate(t.Context(), time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), "EUR")
+	if err != nil {
+		t.Fatalf("EffectiveRate() error = %v", err)
+	}
+
+	if cur.Code != "EUR" || cur.Value != 19.5 {
+		t.Errorf("unexpected currency %+v", cur)
+	}
+	if want := time.Date(2025, 1, 3, 0, 0, 0, 0, bnm.Location()); !effective.Equal(want) {
+		t.Errorf("effective date = %v, want %v", effective, want)
+	}
+	if len(upstream.calls) != 1 || upstream.calls[0] != "03.01.2025" {
+		t.Errorf("expected a single request for Friday, got %v", upstream.calls)
+	}
+}
+
+func TestEffectiveRate_DayOffMissingFromCalendar(t *testing.T) {
+	// Thursday 9 January 2025 is closed but not in the calendar; the 7th
+	// and 8th are Orthodox Christmas, so the rates in force are from the 6th.
+	upstream := &closedUpstream{closed: map[string]bool{"07.01.2025": true, "08.01.2025": true, "09.01.2025": true}}
+	client := bnm.NewClient(
+		bnm.WithGetRequest(upstream.get),
+		bnm.WithDatePolicy(bnm.DateReject),
+	)
+	date := time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	for range 2 {
+		_, effective, err := client.EffectiveRate(t.Context(), date, "EUR")
+		if err != nil {
+			t.Fatalf("EffectiveRate() error = %v", err)
+		}
+		if want := time.Date(2025, 1, 6, 0, 0, 0, 0, bnm.Location()); !effective.Equal(want) {
+			t.Errorf("effective date = %v, want %v", effective, want)
+		}
+	}
+
+	// The second lookup goes straight to the remembered effective date.
+	want := []string{"09.01.2025", "06.01.2025", "06.01.2025"}
+	if len(upstream.calls) != len(want) {
+		t.Fatalf("requests = %v, want %v", upstream.calls, want)
+	}
+	for i := range want {
+		if upstream.calls[i] != want[i] {
+			t.Errorf("requests = %v, want %v", upstream.calls, want)
+			break
+		}
+	}
+}
+
+func TestEffectiveRate_MaxLookBack(t *testing.T) {
+	upstream := &closedUpstream{empty: true}
+	client := bnm.NewClient(
+		bnm.WithGetRequest(upstream.get),
+		bnm.WithMaxLookBack(5),
+	)
+
+	_, _, err := client.EffectiveRate(t.Context(), time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), "EUR")
+	if !errors.Is(err, bnm.ErrNoRatesPublished) {
+		t.Fatalf("expected ErrNoRatesPublished, got %v", err)
+	}
+
+	// 10, 9 and 6 January are publication days within five days.
+	if len(upstream.calls) != 3 {
+		t.Errorf("expected 3 requests, got %v", upstream.calls)
+	}
+}
+
+func TestEffectiveRate_InvalidMaxLookBack(t *testing.T) {
+	client := bnm.NewClient(bnm.WithMaxLookBack(0))
+
+	if _, _, err := client.EffectiveRate(t.Context(), time.Now(), "EUR"); err == nil {
+		t.Error("expected an error for a non-positive look-back")
+	}
+}
+
+func TestEffectiveRate_CurrencyNotFound(t *testing.T) {
+	upstream := &closedUpstream{}
+	client := bnm.NewClient(bnm.WithGetRequest(upstream.get))
+
+	_, _, err := client.EffectiveRate(t.Context(), time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), "XYZ")
+	if !errors.Is(err, bnm.ErrCurrencyNotFound) {
+		t.Errorf("expected ErrCurrencyNotFound, got %v", err)
+	}
+}
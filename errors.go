@@ -3,5 +3,20 @@ package bnm
 import "errors"
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound     = errors.New("not found")
+	ErrBodyTooLarge = errors.New("response body too large")
+	ErrCircuitOpen  = errors.New("circuit breaker is open")
+
+	// ErrUnexpectedContent is returned when the payload is not a BNM exchange
+	// rates document, e.g. an HTML maintenance page or an empty body.
+	ErrUnexpectedContent = errors.New("unexpected content")
+
+	// ErrNoRatesPublished is returned when the document contains no exchange rates.
+	ErrNoRatesPublished = errors.New("no rates published")
+
+	// ErrDateMismatch is returned with DateReject when the response date differs from the requested one.
+	ErrDateMismatch = errors.New("date mismatch")
+
+	// ErrCurrencyNotFound is returned when the requested currency is missing from the rates.
+	ErrCurrencyNotFound = errors.New("currency not found")
 )
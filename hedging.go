@@ -0,0 +1,109 @@
+package bnm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// hedgeBudget limits hedged requests to a fraction of all requests.
+// Every request earns ratio tokens, up to one, and every hedge costs one token.
+type hedgeBudget struct {
+	ratio  float64
+	tokens float64
+	mu     sync.Mutex
+}
+
+// earn credits the budget for a new request.
+func (b *hedgeBudget) earn() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(1, b.tokens+b.ratio)
+}
+
+// take reports whether a hedge is allowed, consuming a token if so.
+func (b *hedgeBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// HedgingMiddleware returns a Middleware that sends a second, identical request
+// if the first has not completed after delay, and returns whichever succeeds
+// first. The slower request is canceled through its context.
+//
+// budget is the maximum ratio of hedged to total requests, in the range (0, 1];
+// e.g. 0.1 allows at most one hedge per ten requests. A request failing before
+// the delay is not hedged; retrying is left to RetryMiddleware.
+func HedgingMiddleware(delay time.Duration, budget float64) Middleware {
+	b := &hedgeBudget{ratio: budget, tokens: 1}
+
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			b.earn()
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			type result struct {
+				body []byte
+				err  error
+			}
+			results := make(chan result, 2)
+			launch := func() {
+				go func() {
+					body, err := next(ctx, url)
+					results <- result{body, err}
+				}()
+			}
+
+			launch()
+			inflight := 1
+
+			timer := clockFromContext(ctx).NewTimer(delay)
+			defer timer.Stop()
+
+			var firstErr error
+			for {
+				select {
+				case r := <-results:
+					inflight--
+					if r.err == nil {
+						return r.body, nil
+					}
+					if firstErr == nil {
+						firstErr = r.err
+					}
+					if inflight == 0 {
+						return nil, firstErr
+					}
+				case <-timer.C():
+					if b.take() {
+						launch()
+						inflight++
+					}
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+func validateHedging(delay time.Duration, budget float64) error {
+	if delay <= 0 {
+		return errors.New("delay must be positive")
+	}
+	if budget <= 0 || budget > 1 {
+		return errors.New("budget must be in the range (0, 1]")
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package bnm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+// stallFirst returns a GetRequestFunc whose odd calls block until canceled,
+// then report it on canceled, and whose even calls succeed immediately.
+func stallFirst(calls *atomic.Int32, canceled chan<- struct{}) bnm.GetRequestFunc {
+	return func(ctx context.Context, _ string) ([]byte, error) {
+		if calls.Add(1)%2 == 1 {
+			<-ctx.Done()
+			canceled <- struct{}{}
+			return nil, ctx.Err()
+		}
+		return []byte("ok"), nil
+	}
+}
+
+// hedgingClient returns a client hedging requests to get on a fake clock.
+func hedgingClient(delay time.Duration, budget float64, get bnm.GetRequestFunc) (*bnm.Client, *bnmtest.FakeClock) {
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 6, 10, 0, 0, 0, bnm.Location()))
+	client := bnm.NewClient(
+		bnm.WithClock(clock),
+		bnm.WithMiddleware(bnm.HedgingMiddleware(delay, budget)),
+		bnm.WithGetRequest(get),
+	)
+
+	return client, clock
+}
+
+// fetchRaw starts a FetchRaw of the client and returns the channel receiving its outcome.
+func fetchRaw(ctx context.Context, client *bnm.Client) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		body, _, err := client.FetchRaw(ctx, dummyQuery())
+		if err == nil && string(body) != "ok" {
+			err = fmt.Errorf("unexpected body %q", body)
+		}
+		done <- err
+	}()
+
+	return done
+}
+
+func TestHedgingMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{}, 2)
+	client, clock := hedgingClient(time.Second, 1, stallFirst(&calls, canceled))
+
+	done := fetchRaw(t.Context(), client)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("want 2 calls, got %d", calls.Load())
+	}
+
+	// The stalled request is canceled once the hedge has won.
+	<-canceled
+}
+
+func TestHedgingMiddleware_Budget(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{}, 2)
+	client, clock := hedgingClient(time.Second, 0.5, stallFirst(&calls, canceled))
+
+	// The first request is hedged.
+	done := fetchRaw(t.Context(), client)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-canceled
+
+	// The second one has no budget left and stalls until canceled.
+	ctx, cancel := context.WithCancel(t.Context())
+	done = fetchRaw(ctx, client)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	<-canceled
+	if calls.Load() != 3 {
+		t.Errorf("want 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestHedgingMiddleware_FastFailureNotHedged(t *testing.T) {
+	var calls atomic.Int32
+	client, clock := hedgingClient(time.Second, 1, func(_ context.Context, _ string) ([]byte, error) {
+		calls.Add(1)
+		return nil, errors.New("boom")
+	})
+
+	if err := <-fetchRaw(t.Context(), client); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	// The hedge timer is stopped with the request, so nothing is sent later.
+	if n := clock.Waiters(); n != 0 {
+		t.Errorf("want no pending timer, got %d", n)
+	}
+	clock.Advance(time.Second)
+	if calls.Load() != 1 {
+		t.Errorf("want 1 call, got %d", calls.Load())
+	}
+}
+
+func TestWithHedging_Invalid(t *testing.T) {
+	for _, opt := range []bnm.Option{bnm.WithHedging(0, 0.1), bnm.WithHedging(time.Second, 0), bnm.WithHedging(time.Second, 2)} {
+		if _, err := bnm.NewClient(opt).Fetch(t.Context(), dummyQuery()); err == nil {
+			t.Error("expected error, got nil")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package atomicfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OsoianMarcel/bnm-go/v2/internal/atomicfile"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := atomicfile.Write(name, []byte(content)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if data, err := os.ReadFile(name); err != nil || string(data) != content {
+			t.Errorf("ReadFile() = %q, %v, want %q", data, err, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected no temporary file left, got %v, %v", entries, err)
+	}
+}
+
+func TestWrite_MissingDirectory(t *testing.T) {
+	if err := atomicfile.Write(filepath.Join(t.TempDir(), "missing", "file"), nil); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
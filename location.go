@@ -0,0 +1,26 @@
+package bnm
+
+import "time"
+
+// locationName is the time zone of the National Bank of Moldova.
+const locationName = "Europe/Chisinau"
+
+var location = loadLocation()
+
+// Location returns the Europe/Chisinau time zone in which BNM publishes rates.
+//
+// If the time zone database is not available on the system, a fixed UTC+2
+// zone without daylight saving time is returned instead. Import time/tzdata
+// in the main package to embed the database into the program.
+func Location() *time.Location {
+	return location
+}
+
+func loadLocation() *time.Location {
+	loc, err := time.LoadLocation(locationName)
+	if err != nil {
+		return time.FixedZone("EET", 2*60*60)
+	}
+
+	return loc
+}
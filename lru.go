@@ -0,0 +1,74 @@
+package bnm
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a fixed-capacity least recently used map.
+// It is safe for concurrent use by multiple goroutines.
+type lru[V any] struct {
+	capacity int
+	data     map[string]*list.Element
+	ll       *list.List
+	mu       sync.Mutex
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRU[V any](capacity int) *lru[V] {
+	return &lru[V]{
+		capacity: capacity,
+		data:     make(map[string]*list.Element, capacity),
+		ll:       list.New(),
+	}
+}
+
+// set stores the value under the key, overwriting any existing value,
+// and evicts the least recently used entry when over capacity.
+func (c *lru[V]) set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.data[key]; found {
+		c.ll.MoveToFront(elem)
+		elem.Value.(*lruEntry[V]).value = value
+		return
+	}
+
+	elem := c.ll.PushFront(&lruEntry[V]{key, value})
+	c.data[key] = elem
+
+	if c.ll.Len() > c.capacity {
+		c.evictOldest()
+	}
+}
+
+// get returns the value stored under the key and marks it as most recently used.
+func (c *lru[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.data[key]
+	if !found {
+		var zero V
+		return zero, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*lruEntry[V]).value, true
+}
+
+// evictOldest removes the least recently used item.
+// Must be called with mutex held.
+func (c *lru[V]) evictOldest() {
+	backElem := c.ll.Back()
+	if backElem != nil {
+		backEntry := backElem.Value.(*lruEntry[V])
+		delete(c.data, backEntry.key)
+		c.ll.Remove(backElem)
+	}
+}
@@ -14,41 +14,66 @@ type MemoryCache struct {
 	data     map[string]*list.Element
 	ll       *list.List
 	mu       sync.Mutex
+	clock    Clock
 }
 
 type cacheEntry struct {
 	key   string
 	value Response
+	meta  Meta
 }
 
-var _ Cache = (*MemoryCache)(nil)
+var _ MetaCache = (*MemoryCache)(nil)
+
+// MemoryCacheOption configures a MemoryCache.
+type MemoryCacheOption func(*MemoryCache)
+
+// WithCacheClock sets the Clock of the cache.
+// It defaults to the system clock.
+func WithCacheClock(clock Clock) MemoryCacheOption {
+	return func(c *MemoryCache) { c.clock = clock }
+}
 
 // NewMemoryCache creates and returns a new MemoryCache instance.
-func NewMemoryCache(capacity int) (*MemoryCache, error) {
+func NewMemoryCache(capacity int, opts ...MemoryCacheOption) (*MemoryCache, error) {
 	if capacity <= 0 {
 		return nil, errors.New("capacity must be positive")
 	}
 
-	return &MemoryCache{
+	c := &MemoryCache{
 		capacity: capacity,
 		data:     make(map[string]*list.Element, capacity),
 		ll:       list.New(),
-	}, nil
+		clock:    systemClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
 }
 
 // Set stores a Response in the memory cache under the specified key.
 // It overwrites any existing value for that key.
 func (c *MemoryCache) Set(ctx context.Context, key string, res Response) error {
+	return c.SetWithMeta(ctx, key, res, Meta{})
+}
+
+// SetWithMeta stores a Response and its Meta in the memory cache under the specified key.
+// It overwrites any existing value for that key.
+func (c *MemoryCache) SetWithMeta(ctx context.Context, key string, res Response, meta Meta) error {
+	entry := &cacheEntry{key: key, value: res, meta: meta}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if elem, found := c.data[key]; found {
 		c.ll.MoveToFront(elem)
-		elem.Value.(*cacheEntry).value = res
+		elem.Value = entry
 		return nil
 	}
 
-	elem := c.ll.PushFront(&cacheEntry{key, res})
+	elem := c.ll.PushFront(entry)
 	c.data[key] = elem
 
 	if c.ll.Len() > c.capacity {
@@ -62,16 +87,25 @@ func (c *MemoryCache) Set(ctx context.Context, key string, res Response) error {
 // If the key does not exist, it returns ErrNotFound.
 // Note: This updates the LRU order (moves item to front).
 func (c *MemoryCache) Get(ctx context.Context, key string) (Response, error) {
+	res, _, err := c.GetWithMeta(ctx, key)
+	return res, err
+}
+
+// GetWithMeta retrieves a Response and its Meta from the memory cache by key.
+// If the key does not exist, it returns ErrNotFound.
+// Note: This updates the LRU order (moves item to front).
+func (c *MemoryCache) GetWithMeta(ctx context.Context, key string) (Response, Meta, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	elem, found := c.data[key]
 	if !found {
-		return Response{}, ErrNotFound
+		return Response{}, Meta{}, ErrNotFound
 	}
 
+	entry := elem.Value.(*cacheEntry)
 	c.ll.MoveToFront(elem)
-	return elem.Value.(*cacheEntry).value, nil
+	return entry.value, entry.meta, nil
 }
 
 // evictOldest removes the least recently used item.
@@ -2,10 +2,10 @@ package bnm_test
 
 import (
 	"errors"
-	"sync"
 	"testing"
 
 	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/cachetest"
 )
 
 func TestNewMemoryCache(t *testing.T) {
@@ -61,103 +61,14 @@ func TestNewMemoryCache(t *testing.T) {
 	}
 }
 
-func TestMemoryCache_SetGet(t *testing.T) {
-	t.Parallel()
-
-	cache, err := bnm.NewMemoryCache(5)
-	if err != nil {
-		t.Fatalf("failed to create memory cache: %v", err)
-	}
-
-	ctx := t.Context()
-
-	tests := []struct {
-		name     string
-		key      string
-		response bnm.Response
-	}{
-		{
-			name: "simple set and get",
-			key:  "key1",
-			response: bnm.Response{
-				Name: "Test Currency",
-			},
-		},
-		{
-			name: "another entry",
-			key:  "key2",
-			response: bnm.Response{
-				Name: "Another Currency",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := cache.Set(ctx, tt.key, tt.response); err != nil {
-				t.Fatalf("Set() error = %v", err)
-			}
-
-			got, err := cache.Get(ctx, tt.key)
-			if err != nil {
-				t.Fatalf("Get() error = %v", err)
-			}
-
-			if got.Name != tt.response.Name {
-				t.Errorf("Get() Name = %v, want %v", got.Name, tt.response.Name)
-			}
-		})
-	}
-}
-
-func TestMemoryCache_Update(t *testing.T) {
-	t.Parallel()
-
-	cache, err := bnm.NewMemoryCache(3)
-	if err != nil {
-		t.Fatalf("failed to create memory cache: %v", err)
-	}
-
-	ctx := t.Context()
-	key := "update-key"
-
-	// Set initial value
-	initial := bnm.Response{Name: "initial"}
-	if err := cache.Set(ctx, key, initial); err != nil {
-		t.Fatalf("Set() initial error = %v", err)
-	}
-
-	// Update value
-	updated := bnm.Response{Name: "updated"}
-	if err := cache.Set(ctx, key, updated); err != nil {
-		t.Fatalf("Set() update error = %v", err)
-	}
-
-	// Verify updated value
-	got, err := cache.Get(ctx, key)
-	if err != nil {
-		t.Fatalf("Get() error = %v", err)
-	}
-
-	if got.Name != "updated" {
-		t.Errorf("Get() Name = %v, want %v", got.Name, "updated")
-	}
-}
-
-func TestMemoryCache_GetNotFound(t *testing.T) {
-	t.Parallel()
-
-	cache, err := bnm.NewMemoryCache(3)
-	if err != nil {
-		t.Fatalf("failed to create memory cache: %v", err)
-	}
-
-	ctx := t.Context()
-
-	_, err = cache.Get(ctx, "nonexistent-key")
-	if !errors.Is(err, bnm.ErrNotFound) {
-		t.Errorf("Get() error = %v, want %v", err, bnm.ErrNotFound)
-	}
+func TestMemoryCache_Conformance(t *testing.T) {
+	cachetest.Run(t, func() bnm.Cache {
+		cache, err := bnm.NewMemoryCache(100)
+		if err != nil {
+			t.Fatalf("failed to create memory cache: %v", err)
+		}
+		return cache
+	})
 }
 
 func TestMemoryCache_LRUEviction(t *testing.T) {
@@ -256,49 +167,6 @@ func TestMemoryCache_LRUEvictionOnGet(t *testing.T) {
 	}
 }
 
-func TestMemoryCache_Concurrent(t *testing.T) {
-	t.Parallel()
-
-	cache, err := bnm.NewMemoryCache(100)
-	if err != nil {
-		t.Fatalf("failed to create memory cache: %v", err)
-	}
-
-	ctx := t.Context()
-	var wg sync.WaitGroup
-	goroutines := 50
-	opsPerGoroutine := 100
-
-	// Concurrent writes
-	for i := 0; i < goroutines; i++ {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			for j := 0; j < opsPerGoroutine; j++ {
-				key := string(rune('A' + (id+j)%26))
-				resp := bnm.Response{Name: key}
-				if err := cache.Set(ctx, key, resp); err != nil {
-					t.Errorf("Set() error = %v", err)
-				}
-			}
-		}(i)
-	}
-
-	// Concurrent reads
-	for i := 0; i < goroutines; i++ {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			for j := 0; j < opsPerGoroutine; j++ {
-				key := string(rune('A' + (id+j)%26))
-				cache.Get(ctx, key) // Error is expected for some keys
-			}
-		}(i)
-	}
-
-	wg.Wait()
-}
-
 func BenchmarkMemoryCache_Set(b *testing.B) {
 	cache, _ := bnm.NewMemoryCache(1000)
 	ctx := b.Context()
@@ -355,3 +223,31 @@ func BenchmarkMemoryCache_GetParallel(b *testing.B) {
 		}
 	})
 }
+
+func TestMemoryCache_SetGetWithMeta(t *testing.T) {
+	t.Parallel()
+
+	cache, err := bnm.NewMemoryCache(2)
+	if err != nil {
+		t.Fatalf("failed to create memory cache: %v", err)
+	}
+
+	ctx := t.Context()
+	meta := bnm.Meta{SourceURL: "https://www.bnm.md/en/official_exchange_rates", SHA256: "abc", Attempts: 1}
+
+	if err := cache.SetWithMeta(ctx, "key", bnm.Response{Name: "one"}, meta); err != nil {
+		t.Fatalf("SetWithMeta() error = %v", err)
+	}
+
+	got, gotMeta, err := cache.GetWithMeta(ctx, "key")
+	if err != nil {
+		t.Fatalf("GetWithMeta() error = %v", err)
+	}
+	if got.Name != "one" || gotMeta != meta {
+		t.Errorf("GetWithMeta() = %+v, %+v, want %+v", got, gotMeta, meta)
+	}
+
+	if _, _, err := cache.GetWithMeta(ctx, "missing"); !errors.Is(err, bnm.ErrNotFound) {
+		t.Errorf("GetWithMeta() error = %v, want %v", err, bnm.ErrNotFound)
+	}
+}
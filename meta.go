@@ -0,0 +1,85 @@
+package bnm
+
+import "time"
+
+// DatePolicy controls how Client.Fetch handles a response whose date differs
+// from the requested date, as happens for weekends, holidays and future dates.
+type DatePolicy int
+
+const (
+	// DateAccept returns the response and caches it under the requested date.
+	DateAccept DatePolicy = iota
+
+	// DateReject fails with ErrDateMismatch and caches nothing.
+	DateReject
+
+	// DateCacheEffective returns the response but caches it under its
+	// effective date only, so the requested date is fetched again next time.
+	DateCacheEffective
+)
+
+// CacheStatus tells whether a response came from a cache, and which one.
+type CacheStatus string
+
+const (
+	// CacheMiss means the response was fetched from the upstream.
+	CacheMiss CacheStatus = "miss"
+
+	// CacheHit means the response came from the cache set with WithCache.
+	CacheHit CacheStatus = "hit"
+
+	// CacheFallback means the response came from the fallback cache of an open circuit breaker.
+	CacheFallback CacheStatus = "fallback"
+
+	// CacheRevalidated means the response came from the cache set with
+	// WithCache after the upstream answered 304 Not Modified.
+	CacheRevalidated CacheStatus = "revalidated"
+)
+
+// Meta describes the provenance of a response returned by Client.FetchWithMeta.
+// For cache hits, the provenance fields describe the original upstream fetch
+// when the cache implements MetaCache.
+type Meta struct {
+	// RequestedDate is the day of the query in Europe/Chisinau, as returned by Query.Day.
+	RequestedDate time.Time `json:"requested_date"`
+
+	// EffectiveDate is the date of the rates as reported by the response.
+	// It is zero if the response date cannot be parsed.
+	EffectiveDate time.Time `json:"effective_date"`
+
+	// SourceURL is the URL the response was fetched from.
+	SourceURL string `json:"source_url,omitempty"`
+
+	// FetchedAt is when the response was fetched from the upstream.
+	FetchedAt time.Time `json:"fetched_at"`
+
+	// Cache tells whether the response came from a cache.
+	Cache CacheStatus `json:"cache,omitempty"`
+
+	// HTTPStatus is the status code of the upstream answer.
+	// It is zero when unknown, e.g. with a custom GetRequestFunc.
+	HTTPStatus int `json:"http_status,omitempty"`
+
+	// SHA256 is the hex-encoded SHA-256 hash of the upstream response body.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Attempts is the number of upstream requests made, including retries and hedged requests.
+	Attempts int `json:"attempts,omitempty"`
+
+	// ETag and LastModified are the validators of the upstream answer, used to
+	// revalidate the response with a conditional request.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// DateMismatch reports whether the effective date is known and falls on
+// a different day than the requested date.
+func (m Meta) DateMismatch() bool {
+	if m.EffectiveDate.IsZero() {
+		return false
+	}
+
+	ry, rm, rd := m.RequestedDate.Date()
+	ey, em, ed := m.EffectiveDate.Date()
+	return ry != ey || rm != em || rd != ed
+}
@@ -0,0 +1,48 @@
+package bnm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+func TestMeta_DateMismatch(t *testing.T) {
+	chisinau := time.FixedZone("EET", 2*60*60)
+
+	tests := []struct {
+		name string
+		meta bnm.Meta
+		want bool
+	}{
+		{
+			name: "same day",
+			meta: bnm.Meta{
+				RequestedDate: time.Date(2025, 1, 3, 15, 30, 0, 0, chisinau),
+				EffectiveDate: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			want: false,
+		},
+		{
+			name: "different day",
+			meta: bnm.Meta{
+				RequestedDate: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+				EffectiveDate: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			want: true,
+		},
+		{
+			name: "unknown effective date",
+			meta: bnm.Meta{RequestedDate: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC)},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.DateMismatch(); got != tt.want {
+				t.Errorf("DateMismatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
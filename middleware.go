@@ -0,0 +1,99 @@
+package bnm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Middleware wraps a GetRequestFunc to add behaviour around the upstream request,
+// such as logging, retries or rate limiting.
+type Middleware func(next GetRequestFunc) GetRequestFunc
+
+// chainMiddleware wraps fn with the middlewares. The first middleware is the
+// outermost one, i.e. it is called first and sees the final result.
+func chainMiddleware(fn GetRequestFunc, mws ...Middleware) GetRequestFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		fn = mws[i](fn)
+	}
+
+	return fn
+}
+
+// LoggingMiddleware returns a Middleware that calls fn after every request
+// with the URL, the duration of the request and its error, if any.
+func LoggingMiddleware(fn func(url string, d time.Duration, err error)) Middleware {
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			clock := clockFromContext(ctx)
+			start := clock.Now()
+			body, err := next(ctx, url)
+			fn(url, clock.Now().Sub(start), err)
+			return body, err
+		}
+	}
+}
+
+// TimeoutMiddleware returns a Middleware that limits every call of the wrapped
+// GetRequestFunc to the given duration.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, url)
+		}
+	}
+}
+
+// RetryMiddleware returns a Middleware that retries failed requests up to
+// attempts times in total, waiting backoff before the first retry and doubling
+// the wait after each further failure. It stops early when the context is done.
+//
+// Only failures that may be transient are retried: transport errors, and
+// StatusErrors of 5xx and 429 Too Many Requests answers, after waiting at least
+// their RetryAfter delay. Other status codes, ErrBodyTooLarge,
+// ErrUnexpectedContent, ErrCircuitOpen and ErrNotRecorded are returned at once.
+func RetryMiddleware(attempts int, backoff time.Duration) Middleware {
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			wait := backoff
+			for attempt := 1; ; attempt++ {
+				body, err := next(ctx, url)
+				if err == nil {
+					return body, nil
+				}
+				retry, retryAfter := retryable(err)
+				if !retry || attempt >= attempts || ctx.Err() != nil {
+					return nil, fmt.Errorf("attempt %d: %w", attempt, err)
+				}
+
+				timer := clockFromContext(ctx).NewTimer(max(wait, retryAfter))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, fmt.Errorf("attempt %d: %w", attempt, err)
+				case <-timer.C():
+				}
+				wait *= 2
+			}
+		}
+	}
+}
+
+// retryable reports whether a failed request may succeed when retried, and
+// the delay requested by the upstream before doing so.
+func retryable(err error) (bool, time.Duration) {
+	var serr *StatusError
+	switch {
+	case errors.As(err, &serr):
+		return serr.StatusCode >= 500 || serr.StatusCode == http.StatusTooManyRequests, serr.RetryAfter
+	case errors.Is(err, ErrBodyTooLarge), errors.Is(err, ErrUnexpectedContent),
+		errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrNotRecorded):
+		return false, 0
+	}
+
+	return true, 0
+}
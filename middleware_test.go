@@ -0,0 +1,200 @@
+package bnm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/bnmtest"
+)
+
+func TestWithMiddleware_Order(t *testing.T) {
+	var calls []string
+	record := func(name string) bnm.Middleware {
+		return func(next bnm.GetRequestFunc) bnm.GetRequestFunc {
+			return func(ctx context.Context, url string) ([]byte, error) {
+				calls = append(calls, name+" before")
+				body, err := next(ctx, url)
+				calls = append(calls, name+" after")
+				return body, err
+			}
+		}
+	}
+
+	client := bnm.NewClient(
+		bnm.WithMiddleware(record("first"), record("second")),
+		bnm.WithMiddleware(record("third")),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			calls = append(calls, "request")
+			return nil, nil
+		}),
+		bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+			return dummyResponse(), nil
+		}),
+	)
+
+	if _, err := client.Fetch(t.Context(), dummyQuery()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "first before,second before,third before,request,third after,second after,first after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		attempts  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success first time", 0, 3, 1, false},
+		{"success after retries", 2, 3, 3, false},
+		{"all attempts fail", 5, 3, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fn := bnm.RetryMiddleware(tt.attempts, time.Millisecond)(func(_ context.Context, _ string) ([]byte, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, errors.New("temporary")
+				}
+				return []byte("ok"), nil
+			})
+
+			body, err := fn(t.Context(), "http://example.com")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantErr && string(body) != "ok" {
+				t.Errorf("want %q, got %q", "ok", body)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("want %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryMiddleware_Retryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"transport error", errors.New("connection reset"), 3},
+		{"server error", &bnm.StatusError{StatusCode: http.StatusBadGateway}, 3},
+		{"too many requests", &bnm.StatusError{StatusCode: http.StatusTooManyRequests}, 3},
+		{"not found", &bnm.StatusError{StatusCode: http.StatusNotFound}, 1},
+		{"body too large", fmt.Errorf("read body: %w", bnm.ErrBodyTooLarge), 1},
+		{"unexpected content", bnm.ErrUnexpectedContent, 1},
+		{"circuit open", bnm.ErrCircuitOpen, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fn := bnm.RetryMiddleware(3, time.Millisecond)(func(_ context.Context, _ string) ([]byte, error) {
+				calls++
+				return nil, tt.err
+			})
+
+			if _, err := fn(t.Context(), "http://example.com"); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("want %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryMiddleware_RetryAfter(t *testing.T) {
+	clock := bnmtest.NewFakeClock(time.Date(2025, 1, 6, 10, 0, 0, 0, bnm.Location()))
+	var calls atomic.Int32
+	client := bnm.NewClient(
+		bnm.WithClock(clock),
+		bnm.WithMiddleware(bnm.RetryMiddleware(2, time.Second)),
+		bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+			if calls.Add(1) == 1 {
+				return nil, &bnm.StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}
+			}
+			return []byte("ok"), nil
+		}),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := client.FetchRaw(t.Context(), dummyQuery())
+		done <- err
+	}()
+
+	// The retry waits for the Retry-After delay rather than the backoff.
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if clock.Waiters() != 1 || calls.Load() != 1 {
+		t.Fatalf("retried before the Retry-After delay: %d calls", calls.Load())
+	}
+	clock.Advance(time.Minute - time.Second)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("want 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestRetryMiddleware_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	calls := 0
+	fn := bnm.RetryMiddleware(5, time.Hour)(func(_ context.Context, _ string) ([]byte, error) {
+		calls++
+		cancel()
+		return nil, errors.New("temporary")
+	})
+
+	if _, err := fn(ctx, "http://example.com"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("want 1 call, got %d", calls)
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	fn := bnm.TimeoutMiddleware(time.Millisecond)(func(ctx context.Context, _ string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if _, err := fn(t.Context(), "http://example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var gotURL string
+	var gotErr error
+	fn := bnm.LoggingMiddleware(func(url string, _ time.Duration, err error) {
+		gotURL, gotErr = url, err
+	})(func(_ context.Context, _ string) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+
+	fn(t.Context(), "http://example.com")
+	if gotURL != "http://example.com" || gotErr == nil {
+		t.Errorf("unexpected log call: url=%q err=%v", gotURL, gotErr)
+	}
+}
@@ -0,0 +1,204 @@
+package offline
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+var (
+	// ErrNotCovered is returned for queries of dates outside the range of the dataset.
+	ErrNotCovered = errors.New("not covered by the offline dataset")
+
+	// ErrMissingDate is returned for queries of dates within the range of the
+	// dataset that it holds no document for, e.g. gaps of the archive it was
+	// generated from.
+	ErrMissingDate = errors.New("date not in the offline dataset")
+)
+
+const (
+	dateFormat = "2006-01-02"
+
+	// queryDateFormat is the date format of query IDs and request URLs.
+	queryDateFormat = "02.01.2006"
+
+	// maxLineSize is the maximum size of a single dataset record.
+	maxLineSize = 4 << 20
+)
+
+// record is a single line of a dataset.
+type record struct {
+	Date string `json:"date"`
+	Lang string `json:"lang"`
+	Body string `json:"body"`
+}
+
+// Dataset is a read-only set of original BNM documents indexed by query.
+// It is safe for concurrent use by multiple goroutines.
+type Dataset struct {
+	bodies      map[string][]byte
+	first, last time.Time
+}
+
+// Load reads a gzip-compressed JSON Lines dataset.
+func Load(r io.Reader) (*Dataset, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress dataset: %w", err)
+	}
+	defer zr.Close()
+
+	d := &Dataset{bodies: make(map[string][]byte)}
+
+	sc := bufio.NewScanner(zr)
+	sc.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for line := 1; sc.Scan(); line++ {
+		var rec record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		date, err := time.ParseInLocation(dateFormat, rec.Date, bnm.Location())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: parse date: %w", line, err)
+		}
+
+		d.bodies[bnm.NewQuery(date, rec.Lang).ID()] = []byte(rec.Body)
+		if d.first.IsZero() || date.Before(d.first) {
+			d.first = date
+		}
+		if date.After(d.last) {
+			d.last = date
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+
+	return d, nil
+}
+
+// LoadFile reads a dataset from a file.
+func LoadFile(name string) (*Dataset, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open dataset: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// LoadFS reads a dataset from a file system, e.g. an embed.FS.
+func LoadFS(fsys fs.FS, name string) (*Dataset, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open dataset: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Len returns the number of documents in the dataset.
+func (d *Dataset) Len() int {
+	return len(d.bodies)
+}
+
+// Range returns the first and last dates covered by the dataset.
+// Both are zero for an empty dataset.
+func (d *Dataset) Range() (first, last time.Time) {
+	return d.first, d.last
+}
+
+// Body returns the original document of the query.
+// It returns an error wrapping ErrNotCovered or ErrMissingDate if the query is
+// not part of the dataset.
+func (d *Dataset) Body(query bnm.Query) ([]byte, error) {
+	return d.body(query)
+}
+
+// GetRequest is a bnm.GetRequestFunc serving documents from the dataset
+// for URLs built by bnm.Client.RequestURL, whatever the base URL.
+func (d *Dataset) GetRequest(ctx context.Context, rawURL string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	lang := path.Base(path.Dir(u.Path))
+	date, err := time.ParseInLocation(queryDateFormat, u.Query().Get("date"), bnm.Location())
+	if path.Base(u.Path) != "official_exchange_rates" || err != nil {
+		return nil, fmt.Errorf("unsupported url %q", rawURL)
+	}
+
+	return d.body(bnm.NewQuery(date, lang))
+}
+
+// NewClient creates a bnm.Client fetching from the dataset.
+// Additional options are applied first, so WithGetRequest cannot be overridden.
+func (d *Dataset) NewClient(opts ...bnm.Option) *bnm.Client {
+	return bnm.NewClient(append(opts, bnm.WithGetRequest(d.GetRequest))...)
+}
+
+func (d *Dataset) body(query bnm.Query) ([]byte, error) {
+	id := query.ID()
+	body, ok := d.bodies[id]
+	if !ok {
+		if d.first.IsZero() {
+			return nil, fmt.Errorf("%s: %w (empty dataset)", id, ErrNotCovered)
+		}
+		if day := query.Day(); !day.Before(d.first) && !day.After(d.last) {
+			return nil, fmt.Errorf("%s: %w", id, ErrMissingDate)
+		}
+		return nil, fmt.Errorf("%s: %w (dataset covers %s to %s)", id, ErrNotCovered, d.first.Format(dateFormat), d.last.Format(dateFormat))
+	}
+
+	return body, nil
+}
+
+// Writer writes a gzip-compressed JSON Lines dataset.
+type Writer struct {
+	zw  *gzip.Writer
+	enc *json.Encoder
+}
+
+// NewWriter creates a Writer writing a dataset to w.
+// Close must be called to flush the dataset.
+func NewWriter(w io.Writer) *Writer {
+	zw, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+	return &Writer{zw: zw, enc: json.NewEncoder(zw)}
+}
+
+// Add writes the original document of the query.
+func (w *Writer) Add(query bnm.Query, raw []byte) error {
+	if err := w.enc.Encode(record{
+		Date: query.Day().Format(dateFormat),
+		Lang: query.Lang,
+		Body: string(raw),
+	}); err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes the dataset. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.zw.Close()
+}
@@ -0,0 +1,133 @@
+package offline_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+	"github.com/OsoianMarcel/bnm-go/v2/offline"
+)
+
+func document(date string, value string) []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?><ValCurs Date="` + date + `" name="Official exchange rate"><Valute ID="47"><NumCode>978</NumCode><CharCode>EUR</CharCode><Nominal>1</Nominal><Name>Euro</Name><Value>` + value + `</Value></Valute></ValCurs>`)
+}
+
+func query(day int) bnm.Query {
+	return bnm.NewQuery(time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+}
+
+func buildDataset(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := offline.NewWriter(&buf)
+	if err := w.Add(query(2), document("02.01.2025", "19.1000")); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := w.Add(query(3), document("03.01.2025", "19.2000")); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDataset_Client(t *testing.T) {
+	ds, err := offline.Load(bytes.NewReader(buildDataset(t)))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if ds.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", ds.Len())
+	}
+	first, last := ds.Range()
+	if first.Day() != 2 || last.Day() != 3 {
+		t.Errorf("Range() = %v, %v", first, last)
+	}
+
+	client := ds.NewClient(bnm.WithBaseURL("https://mirror.internal/bnm"))
+
+	res, err := client.Fetch(t.Context(), query(3))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if eur, ok := res.FindByCode("EUR"); !ok || eur.Value != 19.2 {
+		t.Errorf("unexpected response %+v", res)
+	}
+
+	_, err = client.Fetch(t.Context(), query(4))
+	if !errors.Is(err, offline.ErrNotCovered) {
+		t.Errorf("expected ErrNotCovered, got %v", err)
+	}
+
+	// The dataset covers the 2nd in English only.
+	_, err = client.Fetch(t.Context(), bnm.NewQuery(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), bnm.LANG_RU))
+	if !errors.Is(err, offline.ErrMissingDate) {
+		t.Errorf("expected ErrMissingDate, got %v", err)
+	}
+}
+
+func TestDataset_NonMidnightQuery(t *testing.T) {
+	// 22:30 UTC on the 9th is already the 10th in Chisinau.
+	query := bnm.NewQuery(time.Date(2025, 1, 9, 22, 30, 0, 0, time.UTC), bnm.LANG_EN)
+
+	var buf bytes.Buffer
+	w := offline.NewWriter(&buf)
+	if err := w.Add(query, document("10.01.2025", "19.3000")); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	ds, err := offline.Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if first, _ := ds.Range(); first.Day() != 10 {
+		t.Errorf("Range() starts on %v, want the 10th", first)
+	}
+	if _, err := ds.NewClient().Fetch(t.Context(), query); err != nil {
+		t.Errorf("Fetch() error = %v", err)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{"rates.jsonl.gz": {Data: buildDataset(t)}}
+
+	ds, err := offline.LoadFS(fsys, "rates.jsonl.gz")
+	if err != nil {
+		t.Fatalf("LoadFS() error = %v", err)
+	}
+
+	body, err := ds.Body(query(2))
+	if err != nil {
+		t.Fatalf("Body() error = %v", err)
+	}
+	if !bytes.Equal(body, document("02.01.2025", "19.1000")) {
+		t.Errorf("Body() = %s", body)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	if _, err := offline.Load(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestDataset_GetRequestUnsupportedURL(t *testing.T) {
+	ds, err := offline.Load(bytes.NewReader(buildDataset(t)))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, err := ds.GetRequest(t.Context(), "https://www.bnm.md/en/other"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
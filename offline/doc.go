@@ -0,0 +1,16 @@
+// Package offline serves BNM exchange rates from a historical dataset instead
+// of the network, for environments that cannot reach bnm.md.
+//
+// A dataset is a gzip-compressed JSON Lines file holding the original XML
+// documents of the covered queries. It can be generated from a DirArchiver
+// directory with the "bnm dataset" command, and then loaded from a file or
+// bundled into a program with go:embed:
+//
+//	//go:embed rates.jsonl.gz
+//	var ratesFS embed.FS
+//
+//	ds, err := offline.LoadFS(ratesFS, "rates.jsonl.gz")
+//	client := ds.NewClient()
+//
+// The package does not bundle any data itself.
+package offline
@@ -13,12 +13,23 @@ const (
 )
 
 // Query represents a request for exchange rates on a specific date and in a specific language.
+//
+// A Date at midnight is taken as a calendar day, whatever its location.
+// Any other Date is taken as an instant and requests the day it falls on in
+// Europe/Chisinau, so that time.Now() on a server in another time zone
+// requests the current BNM day.
 type Query struct {
 	Date time.Time
 	Lang string
 }
 
 // NewQuery creates a new Query for the given date and language.
+//
+// A date at exactly midnight requests that calendar day; any other date is an
+// instant requesting its day in Europe/Chisinau. East of Chisinau the rule is
+// discontinuous: 2025-01-06 00:00 JST requests January 6 but 00:00:01 JST,
+// still January 5 in Chisinau, requests January 5. Use NewQueryForDay to
+// request a calendar day unambiguously.
 func NewQuery(date time.Time, lang string) Query {
 	return Query{
 		Date: date,
@@ -26,9 +37,28 @@ func NewQuery(date time.Time, lang string) Query {
 	}
 }
 
-// RequestURL returns the URL used to request exchange rates from the BNM API.
+// NewQueryForDay creates a new Query for the given calendar day and language.
+func NewQueryForDay(year int, month time.Month, day int, lang string) Query {
+	return NewQuery(time.Date(year, month, day, 0, 0, 0, 0, Location()), lang)
+}
+
+// Day returns midnight of the requested day in Europe/Chisinau: the calendar
+// day of Date if it is at midnight, or the day Date falls on in Europe/Chisinau
+// otherwise.
+func (q Query) Day() time.Time {
+	return civilDay(q.Date)
+}
+
+// RequestURL returns the URL used to request exchange rates from the default BNM API endpoint.
+//
+// Deprecated: use Client.RequestURL, which honors the base URL configured with WithBaseURL.
 func (q Query) RequestURL() string {
-	return fmt.Sprintf("http://www.bnm.md/%s/official_exchange_rates?get_xml=1&date=%s", q.Lang, q.dateToStr())
+	return DefaultBaseURL + q.requestPath()
+}
+
+// requestPath returns the path and query string of the BNM API request, relative to the base URL.
+func (q Query) requestPath() string {
+	return fmt.Sprintf("/%s/official_exchange_rates?get_xml=1&date=%s", q.Lang, q.dateToStr())
 }
 
 // ID returns a unique identifier for the query.
@@ -38,5 +68,16 @@ func (q Query) ID() string {
 
 func (q Query) dateToStr() string {
 	const dateFormat = "02.01.2006"
-	return q.Date.Format(dateFormat)
+	return q.Day().Format(dateFormat)
+}
+
+// civilDay returns midnight in Europe/Chisinau of the calendar day of t if t
+// is at midnight, or of the day the instant t falls on in Europe/Chisinau otherwise.
+func civilDay(t time.Time) time.Time {
+	if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+		return DayOf(t)
+	}
+
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location())
 }
@@ -24,7 +24,7 @@ func getSpecificQuery() bnm.Query {
 func TestQuery_RequestURL(t *testing.T) {
 	query := getSpecificQuery()
 
-	expected := "http://www.bnm.md/en/official_exchange_rates?get_xml=1&date=05.08.2017"
+	expected := "https://www.bnm.md/en/official_exchange_rates?get_xml=1&date=05.08.2017"
 	result := query.RequestURL()
 	if result != expected {
 		t.Errorf("incorrect URL, expected: %s, result: %s", expected, result)
@@ -41,3 +41,43 @@ func TestQuery_ID(t *testing.T) {
 		t.Errorf("incorrect id, expected: %s, result: %s", expected, result)
 	}
 }
+
+func TestQuery_TimeZone(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tests := []struct {
+		name string
+		date time.Time
+		want string
+	}{
+		{"UTC instant before midnight in Chisinau", time.Date(2025, 1, 5, 21, 30, 0, 0, time.UTC), "en_05.01.2025"},
+		{"UTC instant after midnight in Chisinau", time.Date(2025, 1, 5, 22, 30, 0, 0, time.UTC), "en_06.01.2025"},
+		{"UTC instant after midnight in Chisinau in summer", time.Date(2025, 7, 5, 21, 30, 0, 0, time.UTC), "en_06.07.2025"},
+		{"instant east of Chisinau", time.Date(2025, 1, 6, 6, 0, 0, 0, tokyo), "en_05.01.2025"},
+		{"calendar day east of Chisinau", time.Date(2025, 1, 6, 0, 0, 0, 0, tokyo), "en_06.01.2025"},
+		{"first nanosecond after midnight east of Chisinau", time.Date(2025, 1, 6, 0, 0, 0, 1, tokyo), "en_05.01.2025"},
+		{"first second after midnight east of Chisinau", time.Date(2025, 1, 6, 0, 0, 1, 0, tokyo), "en_05.01.2025"},
+		{"last nanosecond before midnight east of Chisinau", time.Date(2025, 1, 5, 23, 59, 59, 999999999, tokyo), "en_05.01.2025"},
+		{"calendar day west of Chisinau", time.Date(2025, 1, 6, 0, 0, 0, 0, time.FixedZone("EST", -5*60*60)), "en_06.01.2025"},
+		{"first second after midnight west of Chisinau", time.Date(2025, 1, 6, 0, 0, 1, 0, time.FixedZone("EST", -5*60*60)), "en_06.01.2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bnm.NewQuery(tt.date, bnm.LANG_EN).ID(); got != tt.want {
+				t.Errorf("ID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueryForDay(t *testing.T) {
+	query := bnm.NewQueryForDay(2017, time.August, 5, bnm.LANG_EN)
+
+	if query.ID() != getSpecificQuery().ID() {
+		t.Errorf("incorrect id %s", query.ID())
+	}
+	if day := query.Day(); day.Location() != bnm.Location() || day.Day() != 5 {
+		t.Errorf("unexpected day %v", day)
+	}
+}
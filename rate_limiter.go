@@ -0,0 +1,91 @@
+package bnm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of upstream requests.
+// A single RateLimiter can be shared by multiple Client instances.
+// Time is read from the Clock of the Client making the request, if any.
+// It is safe for concurrent use by multiple goroutines.
+type RateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewRateLimiter creates a RateLimiter allowing rps requests per second on
+// average with bursts of up to burst requests.
+func NewRateLimiter(rps float64, burst int) (*RateLimiter, error) {
+	if rps <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+	if burst <= 0 {
+		return nil, errors.New("burst must be positive")
+	}
+
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}, nil
+}
+
+// Wait blocks until a request is allowed or the context is done.
+// It returns the context error in the latter case.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	clock := clockFromContext(ctx)
+
+	l.mu.Lock()
+	now := clock.Now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+max(0, now.Sub(l.last).Seconds())*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	timer := clock.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Give the reserved token back so that canceled callers don't delay others.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+// RateLimitMiddleware returns a Middleware that waits for the limiter before every request.
+func RateLimitMiddleware(l *RateLimiter) Middleware {
+	return func(next GetRequestFunc) GetRequestFunc {
+		return func(ctx context.Context, url string) ([]byte, error) {
+			if err := l.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limit: %w", err)
+			}
+			return next(ctx, url)
+		}
+	}
+}
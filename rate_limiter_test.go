@@ -0,0 +1,102 @@
+package bnm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OsoianMarcel/bnm-go/v2"
+)
+
+func TestNewRateLimiter_Invalid(t *testing.T) {
+	if _, err := bnm.NewRateLimiter(0, 1); err == nil {
+		t.Error("expected error for zero rate")
+	}
+	if _, err := bnm.NewRateLimiter(1, 0); err == nil {
+		t.Error("expected error for zero burst")
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	l, err := bnm.NewRateLimiter(50, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	for range 4 {
+		if err := l.Wait(t.Context()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	// 2 requests are served by the burst, the other 2 need 20ms each.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected waits of about 40ms, got %v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitCanceled(t *testing.T) {
+	l, err := bnm.NewRateLimiter(0.001, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.Wait(t.Context()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWithRateLimiter_SharedAndCacheHitsNotCounted(t *testing.T) {
+	l, err := bnm.NewRateLimiter(0.001, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	newClient := func() *bnm.Client {
+		cache, _ := bnm.NewMemoryCache(10)
+		return bnm.NewClient(
+			bnm.WithCache(cache),
+			bnm.WithRateLimiter(l),
+			bnm.WithGetRequest(func(_ context.Context, _ string) ([]byte, error) {
+				return nil, nil
+			}),
+			bnm.WithUnmarshaler(func(_ []byte) (bnm.Response, error) {
+				return dummyResponse(), nil
+			}),
+		)
+	}
+
+	first, second := newClient(), newClient()
+	for range 3 {
+		if _, err := first.Fetch(t.Context(), dummyQuery()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := second.Fetch(t.Context(), dummyQuery()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Both tokens are used up by the two clients, so a third upstream request must wait.
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	query := bnm.NewQuery(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), bnm.LANG_EN)
+	if _, err := first.Fetch(ctx, query); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWithRateLimit_Invalid(t *testing.T) {
+	_, err := bnm.NewClient(bnm.WithRateLimit(-1, 1)).Fetch(t.Context(), dummyQuery())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
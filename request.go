@@ -5,36 +5,140 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+const (
+	// DefaultUserAgent is the User-Agent header sent with every request unless overridden.
+	DefaultUserAgent = "bnm-go/v2"
+
+	// DefaultMaxBodySize is the maximum accepted response body size in bytes unless overridden.
+	DefaultMaxBodySize = 10 << 20
+
+	// defaultTimeout is the timeout of the HTTP client used when none is configured.
+	defaultTimeout = 30 * time.Second
+)
+
+// StatusError is returned by the default GetRequestFunc for answers with an
+// unexpected status code.
+type StatusError struct {
+	StatusCode int
+
+	// RetryAfter is the delay requested by the Retry-After header of a
+	// 429 Too Many Requests answer, if any.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code: %d", e.StatusCode)
+}
+
 type clientDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-func getRequest(ctx context.Context, client clientDoer, url string) ([]byte, error) {
+// httpGetter performs GET requests against the BNM API.
+type httpGetter struct {
+	client      clientDoer
+	userAgent   string
+	headers     http.Header
+	maxBodySize int64
+}
+
+func newHTTPGetter() httpGetter {
+	return httpGetter{
+		client:      &http.Client{Timeout: defaultTimeout},
+		userAgent:   DefaultUserAgent,
+		maxBodySize: DefaultMaxBodySize,
+	}
+}
+
+// get performs the request and returns the body of a 200 OK response.
+// Bodies larger than maxBodySize (when positive) are rejected with ErrBodyTooLarge.
+//
+// If the context carries validators of a previous response, the request is made
+// conditional and a 304 Not Modified answer is reported through the context
+// with an empty body instead of an error. The status code of a successful
+// answer is reported through the context as well.
+func (g httpGetter) get(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return []byte{}, fmt.Errorf("create request: %w", err)
 	}
 
-	res, err := client.Do(req)
+	for key, values := range g.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+
+	tr := requestTraceFromContext(ctx)
+	if tr != nil {
+		if tr.etag != "" {
+			req.Header.Set("If-None-Match", tr.etag)
+		}
+		if tr.lastModified != "" {
+			req.Header.Set("If-Modified-Since", tr.lastModified)
+		}
+	}
+
+	res, err := g.client.Do(req)
 	if err != nil {
 		return []byte{}, fmt.Errorf("do request: %w", err)
 	}
 	defer res.Body.Close()
 
+	if tr != nil && res.StatusCode == http.StatusNotModified && (tr.etag != "" || tr.lastModified != "") {
+		tr.setNotModified(res.StatusCode)
+		return []byte{}, nil
+	}
+
 	if res.StatusCode != http.StatusOK {
-		return []byte{}, fmt.Errorf("status code: %d", res.StatusCode)
+		serr := &StatusError{StatusCode: res.StatusCode}
+		if res.StatusCode == http.StatusTooManyRequests {
+			serr.RetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), clockFromContext(ctx).Now())
+		}
+		return []byte{}, serr
+	}
+
+	if g.maxBodySize > 0 && res.ContentLength > g.maxBodySize {
+		return []byte{}, fmt.Errorf("read body: %w", ErrBodyTooLarge)
+	}
+
+	var r io.Reader = res.Body
+	if g.maxBodySize > 0 {
+		r = io.LimitReader(res.Body, g.maxBodySize+1)
 	}
 
-	body, err := io.ReadAll(res.Body)
+	body, err := io.ReadAll(r)
 	if err != nil {
 		return []byte{}, fmt.Errorf("read body: %w", err)
 	}
 
+	if g.maxBodySize > 0 && int64(len(body)) > g.maxBodySize {
+		return []byte{}, fmt.Errorf("read body: %w", ErrBodyTooLarge)
+	}
+
+	if tr != nil {
+		tr.setResponse(res.StatusCode, res.Header.Get("ETag"), res.Header.Get("Last-Modified"))
+	}
+
 	return body, nil
 }
 
-func getRequestWithDefaultClient(ctx context.Context, url string) ([]byte, error) {
-	return getRequest(ctx, http.DefaultClient, url)
+// parseRetryAfter returns the delay of a Retry-After header, given in seconds
+// or as an HTTP date, or zero if it is missing or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0)
+	}
+
+	return 0
 }
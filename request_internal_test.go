@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 type fakeHttpClient struct {
@@ -19,17 +20,26 @@ func (f *fakeHttpClient) Do(_ *http.Request) (*http.Response, error) {
 	return f.res, f.err
 }
 
+// fixedClock is a Clock whose time stands still.
+type fixedClock struct {
+	systemClock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
 type errReader struct{}
 
 func (errReader) Read(p []byte) (int, error) { return 0, errors.New("read error") }
 
-func TestGetRequest(t *testing.T) {
+func TestHTTPGetter_Get(t *testing.T) {
 	tests := []struct {
-		name    string
-		client  clientDoer
-		url     string
-		want    string
-		wantErr string
+		name        string
+		client      clientDoer
+		maxBodySize int64
+		url         string
+		want        string
+		wantErr     string
 	}{
 		{
 			name:    "invalid url",
@@ -52,6 +62,15 @@ func TestGetRequest(t *testing.T) {
 			url:     "http://example.com",
 			wantErr: "status code",
 		},
+		{
+			name: "not modified without validators",
+			client: &fakeHttpClient{res: &http.Response{
+				StatusCode: http.StatusNotModified,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}},
+			url:     "http://example.com",
+			wantErr: "status code: 304",
+		},
 		{
 			name: "body read error",
 			client: &fakeHttpClient{res: &http.Response{
@@ -70,11 +89,44 @@ func TestGetRequest(t *testing.T) {
 			url:  "http://example.com",
 			want: "hello",
 		},
+		{
+			name: "body too large",
+			client: &fakeHttpClient{res: &http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: -1,
+				Body:          io.NopCloser(strings.NewReader("hello")),
+			}},
+			maxBodySize: 4,
+			url:         "http://example.com",
+			wantErr:     ErrBodyTooLarge.Error(),
+		},
+		{
+			name: "content length too large",
+			client: &fakeHttpClient{res: &http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: 100,
+				Body:          io.NopCloser(strings.NewReader("")),
+			}},
+			maxBodySize: 4,
+			url:         "http://example.com",
+			wantErr:     ErrBodyTooLarge.Error(),
+		},
+		{
+			name: "body within limit",
+			client: &fakeHttpClient{res: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("hello")),
+			}},
+			maxBodySize: 5,
+			url:         "http://example.com",
+			want:        "hello",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getRequest(t.Context(), tt.client, tt.url)
+			g := httpGetter{client: tt.client, maxBodySize: tt.maxBodySize}
+			got, err := g.get(t.Context(), tt.url)
 
 			if tt.wantErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
@@ -94,18 +146,52 @@ func TestGetRequest(t *testing.T) {
 	}
 }
 
-func TestGetRequestWithDefaultClient(t *testing.T) {
+func TestHTTPGetter_GetRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		status     int
+		retryAfter string
+		want       time.Duration
+	}{
+		{http.StatusTooManyRequests, "2", 2 * time.Second},
+		{http.StatusTooManyRequests, now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{http.StatusTooManyRequests, "soon", 0},
+		{http.StatusServiceUnavailable, "2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.status, tt.retryAfter), func(t *testing.T) {
+			g := httpGetter{client: &fakeHttpClient{res: &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{"Retry-After": {tt.retryAfter}},
+				Body:       io.NopCloser(strings.NewReader("")),
+			}}}
+			_, err := g.get(withClock(t.Context(), fixedClock{now: now}), "http://example.com")
+
+			var serr *StatusError
+			if !errors.As(err, &serr) || serr.StatusCode != tt.status || serr.RetryAfter != tt.want {
+				t.Errorf("expected status %d with Retry-After %v, got %#v", tt.status, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestHTTPGetter_GetHeaders(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, "ok")
+		fmt.Fprintf(w, "%s|%s", r.Header.Get("User-Agent"), r.Header.Get("X-Api-Key"))
 	}))
 	defer ts.Close()
 
-	body, err := getRequestWithDefaultClient(t.Context(), ts.URL)
+	g := newHTTPGetter()
+	g.headers = http.Header{"X-Api-Key": {"secret"}}
+
+	body, err := g.get(t.Context(), ts.URL)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if string(body) != "ok" {
-		t.Errorf("expected %q, got %q", "ok", string(body))
+	want := DefaultUserAgent + "|secret"
+	if string(body) != want {
+		t.Errorf("expected %q, got %q", want, string(body))
 	}
 }
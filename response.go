@@ -1,10 +1,20 @@
 package bnm
 
 import (
-	"encoding/xml"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 )
 
+// responseDateFormat is the layout of the Date attribute of a BNM exchange rates document.
+const responseDateFormat = "02.01.2006"
+
+// rootElement is the name of the root element of a BNM exchange rates document.
+const rootElement = "ValCurs"
+
 // Currency represents a currency as returned by the official API.
 type Currency struct {
 	ID      string  `xml:"ID,attr" json:"id"`
@@ -34,14 +44,75 @@ func (r Response) FindByCode(code string) (Currency, bool) {
 	return Currency{}, false
 }
 
-// unmarshalResponse parses XML data into a Response struct.
-// Returns an error if the XML cannot be decoded.
-func unmarshalResponse(data []byte) (Response, error) {
-	var res Response
-	err := xml.Unmarshal(data, &res)
+// isoDateFormat is the ISO 8601 layout of dates in JSON documents.
+const isoDateFormat = "2006-01-02"
+
+// MarshalJSON encodes the response with its date in ISO 8601 (YYYY-MM-DD) format.
+// A date that cannot be parsed is encoded unchanged.
+func (r Response) MarshalJSON() ([]byte, error) {
+	type plain Response
+	out := struct {
+		plain
+		Date string `json:"date"`
+	}{plain: plain(r), Date: r.Date}
+
+	if t, err := r.Time(); err == nil {
+		out.Date = t.Format(isoDateFormat)
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a response whose date is either in ISO 8601 (YYYY-MM-DD)
+// or in the BNM "02.01.2006" format, so that JSON encoded by older versions keeps
+// working. The date is stored in the BNM format; other values are kept unchanged.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	type plain Response
+	var in struct {
+		plain
+		Date string `json:"date"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*r = Response(in.plain)
+	r.Date = in.Date
+	if t, err := time.Parse(isoDateFormat, in.Date); err == nil {
+		r.Date = t.Format(responseDateFormat)
+	}
+
+	return nil
+}
+
+// Time parses the Date of the response as midnight in the Europe/Chisinau time zone.
+// It returns an error if the date is not in the BNM "02.01.2006" format.
+func (r Response) Time() (time.Time, error) {
+	t, err := time.ParseInLocation(responseDateFormat, r.Date, Location())
 	if err != nil {
-		return Response{}, fmt.Errorf("unmarshal response: %w", err)
+		return time.Time{}, fmt.Errorf("parse response date: %w", err)
+	}
+
+	return t, nil
+}
+
+// unmarshalResponse parses XML data into a Response struct with the default DecodeOptions.
+// Returns ErrUnexpectedContent if the data is not a BNM exchange rates document,
+// ErrNoRatesPublished if it contains no valid currencies, a *PartialResponseError
+// alongside the response if some currencies are invalid, or an error if the XML
+// cannot be decoded.
+var unmarshalResponse = NewUnmarshaler(DecodeOptions{})
+
+// checkPayload rejects bodies that cannot be a BNM exchange rates document:
+// empty ones and HTML pages.
+func checkPayload(data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("%w: empty body", ErrUnexpectedContent)
+	}
+
+	if ct := http.DetectContentType(data); strings.HasPrefix(ct, "text/html") {
+		return fmt.Errorf("%w: content type %q", ErrUnexpectedContent, ct)
 	}
 
-	return res, nil
+	return nil
 }
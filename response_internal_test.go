@@ -1,6 +1,8 @@
 package bnm
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -12,6 +14,32 @@ func TestResponse_unmarshalResponse_Err(t *testing.T) {
 	}
 }
 
+func TestResponse_unmarshalResponse_InvalidContent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"empty body", "", ErrUnexpectedContent},
+		{"whitespace body", " \n\t", ErrUnexpectedContent},
+		{"html page", "<!DOCTYPE html><html><body>Site under maintenance</body></html>", ErrUnexpectedContent},
+		{"html without doctype", "<html><head><title>Error</title></head></html>", ErrUnexpectedContent},
+		{"unexpected root", `<?xml version="1.0"?><Error>boom</Error>`, ErrUnexpectedContent},
+		{"plain text", "invalid", ErrUnexpectedContent},
+		{"invalid date", `<ValCurs Date="2017-08-05"><Valute ID="47"><CharCode>EUR</CharCode><Value>21.2997</Value></Valute></ValCurs>`, ErrUnexpectedContent},
+		{"no currencies", `<?xml version="1.0"?><ValCurs Date="05.08.2017" name="Cursul oficial de schimb"></ValCurs>`, ErrNoRatesPublished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshalResponse([]byte(tt.data))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
 func TestResponse_unmarshalResponse_Ok(t *testing.T) {
 	xmlData := `
 		<?xml version="1.0" encoding="UTF-8"?>
@@ -80,3 +108,85 @@ func TestResponse_unmarshalResponse_Ok(t *testing.T) {
 		t.Errorf("expected name 'Cursul oficial de schimb', got '%s'", res.Name)
 	}
 }
+
+func TestResponse_unmarshalResponse_LenientNumbers(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float32
+	}{
+		{"21.2997", 21.2997},
+		{"21,2997", 21.2997},
+		{" \n\t21.2997 ", 21.2997},
+		{"1 234,5", 1234.5},
+		{"1.234,5", 1234.5},
+		{"1,234.5", 1234.5},
+		{"1\u00a0234,5", 1234.5},
+		{"7", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			data := `<ValCurs Date="05.08.2017"><Valute ID="47"><CharCode> EUR </CharCode><Value>` + tt.value + `</Value></Valute></ValCurs>`
+			res, err := unmarshalResponse([]byte(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cur, ok := res.FindByCode("EUR"); !ok || cur.Value != tt.want {
+				t.Errorf("expected EUR at %v, got %+v", tt.want, res.Currencies)
+			}
+		})
+	}
+}
+
+func TestResponse_unmarshalResponse_InvalidCurrencies(t *testing.T) {
+	data := []byte(`<ValCurs Date="05.08.2017">
+		<Valute ID="47"><CharCode>EUR</CharCode><Value>21,2997</Value></Valute>
+		<Valute ID="44"><CharCode>USD</CharCode><Value/></Valute>
+		<Valute ID="35"><CharCode>RON</CharCode><Nominal>one</Nominal><Value>4.6680</Value></Valute>
+		<Valute ID="36"><CharCode>RUB</CharCode><Value>-0.3</Value></Valute>
+	</ValCurs>`)
+
+	res, err := unmarshalResponse(data)
+	var partial *PartialResponseError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a PartialResponseError, got %v", err)
+	}
+	if len(res.Currencies) != 1 || res.Currencies[0].Code != "EUR" {
+		t.Errorf("expected only EUR, got %+v", res.Currencies)
+	}
+	if len(partial.Errors) != 3 || partial.Errors[0].Index != 1 || partial.Errors[0].Code != "USD" {
+		t.Errorf("unexpected currency errors %v", partial.Errors)
+	}
+
+	// Strict numbers reject the comma separator too: nothing usable is left.
+	_, err = NewUnmarshaler(DecodeOptions{StrictNumbers: true})(data)
+	if !errors.Is(err, ErrNoRatesPublished) || !errors.As(err, &partial) || len(partial.Errors) != 4 {
+		t.Errorf("expected ErrNoRatesPublished with 4 currency errors, got %v", err)
+	}
+
+	_, err = NewUnmarshaler(DecodeOptions{FailOnInvalidCurrency: true})(data)
+	var cerr *CurrencyError
+	if !errors.As(err, &cerr) || cerr.Code != "USD" || errors.As(err, &partial) {
+		t.Errorf("expected a CurrencyError for USD, got %v", err)
+	}
+}
+
+func TestResponse_unmarshalResponse_Limits(t *testing.T) {
+	doc := `<ValCurs Date="05.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><Value>21.2997</Value></Valute></ValCurs>`
+
+	if _, err := NewUnmarshaler(DecodeOptions{MaxSize: 16})([]byte(doc)); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if _, err := NewUnmarshaler(DecodeOptions{MaxSize: -1})([]byte(doc)); err != nil {
+		t.Errorf("unexpected error without size limit: %v", err)
+	}
+
+	deep := `<ValCurs Date="05.08.2017"><Valute ID="47"><CharCode>EUR</CharCode><Value>21.2997</Value>` +
+		strings.Repeat("<x>", 20) + strings.Repeat("</x>", 20) + `</Valute></ValCurs>`
+	if _, err := unmarshalResponse([]byte(deep)); !errors.Is(err, ErrUnexpectedContent) {
+		t.Errorf("expected ErrUnexpectedContent for deep nesting, got %v", err)
+	}
+	if _, err := NewUnmarshaler(DecodeOptions{MaxDepth: 32})([]byte(deep)); err != nil {
+		t.Errorf("unexpected error with a higher depth limit: %v", err)
+	}
+}
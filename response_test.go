@@ -1,7 +1,10 @@
 package bnm_test
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/OsoianMarcel/bnm-go/v2"
 )
@@ -31,3 +34,53 @@ func TestResponse_FindByCode(t *testing.T) {
 		}
 	})
 }
+
+func TestResponse_Time(t *testing.T) {
+	got, err := bnm.Response{Date: "05.08.2017"}.Time()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2017, 8, 5, 0, 0, 0, 0, bnm.Location()); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := (bnm.Response{Date: "2017-08-05"}).Time(); err == nil {
+		t.Error("expected error for invalid date, got nil")
+	}
+}
+
+func TestResponse_JSON(t *testing.T) {
+	res := bnm.Response{
+		Date:       "05.08.2017",
+		Name:       "Cursul oficial de schimb",
+		Currencies: []bnm.Currency{{ID: "47", Code: "EUR", NumCode: 978, Nominal: 1, Name: "Euro", Value: 21.2997}},
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"date":"2017-08-05"`) {
+		t.Errorf("expected ISO 8601 date, got %s", data)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"iso date", string(data)},
+		{"legacy date", strings.Replace(string(data), "2017-08-05", "05.08.2017", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bnm.Response
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got.Date != res.Date || got.Name != res.Name || len(got.Currencies) != 1 || got.Currencies[0] != res.Currencies[0] {
+				t.Errorf("expected %+v, got %+v", res, got)
+			}
+		})
+	}
+}
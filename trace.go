@@ -0,0 +1,73 @@
+package bnm
+
+import (
+	"context"
+	"sync"
+)
+
+// requestTrace carries information about a single fetch between
+// Client.FetchWithMeta and the GetRequestFunc through the request context:
+// the validators of a previous response and, back, the outcome of the request.
+type requestTrace struct {
+	etag         string
+	lastModified string
+
+	mu              sync.Mutex
+	attempts        int
+	status          int
+	notModified     bool
+	newETag         string
+	newLastModified string
+}
+
+type requestTraceKey struct{}
+
+func withRequestTrace(ctx context.Context, tr *requestTrace) context.Context {
+	return context.WithValue(ctx, requestTraceKey{}, tr)
+}
+
+func requestTraceFromContext(ctx context.Context) *requestTrace {
+	tr, _ := ctx.Value(requestTraceKey{}).(*requestTrace)
+	return tr
+}
+
+// traceAttempts wraps the GetRequestFunc closest to the upstream to count
+// the attempts made for a fetch, including retries and hedged requests.
+func traceAttempts(next GetRequestFunc) GetRequestFunc {
+	return func(ctx context.Context, url string) ([]byte, error) {
+		if tr := requestTraceFromContext(ctx); tr != nil {
+			tr.mu.Lock()
+			tr.attempts++
+			tr.mu.Unlock()
+		}
+		return next(ctx, url)
+	}
+}
+
+// setNotModified records a 304 Not Modified answer.
+func (tr *requestTrace) setNotModified(status int) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.status = status
+	tr.notModified = true
+}
+
+// setResponse records the status code and validators of an answer.
+func (tr *requestTrace) setResponse(status int, etag, lastModified string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.status = status
+	tr.notModified = false
+	tr.newETag = etag
+	tr.newLastModified = lastModified
+}
+
+// result returns the recorded outcome of the fetch.
+func (tr *requestTrace) result() (notModified bool, status, attempts int, etag, lastModified string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	return tr.notModified, tr.status, tr.attempts, tr.newETag, tr.newLastModified
+}